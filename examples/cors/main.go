@@ -1,6 +1,8 @@
 package main
 
 import (
+	"log"
+
 	"github.com/Fuad28/GOServe.git/goserve"
 	"github.com/Fuad28/GOServe.git/goserve/status"
 )
@@ -26,5 +28,7 @@ func main() {
 
 	server.GET("/tasks", getTasksHandler)
 
-	server.StartAndListen()
+	if err := server.StartAndListen(); err != nil {
+		log.Fatal(err)
+	}
 }
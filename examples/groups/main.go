@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log"
+
+	"github.com/Fuad28/GOServe.git/goserve"
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// Groups share a path prefix and middlewares between routes.
+// Group middlewares run after the server middlewares and before the route middlewares.
+// Groups can be nested, and a router built on its own with goserve.NewRouter() can be mounted under a prefix.
+
+func loggingMiddleware(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+	return req.Next(res)
+}
+
+func authenticationMiddlware(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+	if _, exists := req.Headers().Get("Authorization"); !exists {
+		return res.SetStatus(status.HTTP_401_UNAUTHORIZED).Send(
+			goserve.JSON{"message": "unauthorized"},
+		)
+	}
+
+	return req.Next(res)
+}
+
+func getTasksHandler(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+	return res.SetStatus(status.HTTP_200_OK).Send(goserve.JSON{"message": "tasks"})
+}
+
+func getUsersHandler(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+	return res.SetStatus(status.HTTP_200_OK).Send(goserve.JSON{"message": "users"})
+}
+
+// The admin router is built independently, e.g in its own package, and mounted by the server.
+func adminRouter() *goserve.Router {
+	router := goserve.NewRouter(authenticationMiddlware)
+	router.GET("/users", getUsersHandler)
+
+	return router
+}
+
+func main() {
+	server := goserve.NewServer(goserve.Config{
+		Port: 8000,
+	})
+
+	server.AddMiddleWares(loggingMiddleware)
+
+	// GET /api/v1/tasks
+	api := server.Group("/api/v1")
+	api.GET("/tasks", getTasksHandler)
+
+	// GET /api/v1/me/tasks runs loggingMiddleware then authenticationMiddlware
+	me := api.Group("/me", authenticationMiddlware)
+	me.GET("/tasks", getTasksHandler)
+
+	// GET /admin/users
+	server.Mount("/admin", adminRouter())
+
+	if err := server.StartAndListen(); err != nil {
+		log.Fatal(err)
+	}
+}
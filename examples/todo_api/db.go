@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/Fuad28/GOServe.git/goserve/utils"
 )
 
+// Returned when a task doesn't exist or belongs to another user.
+var ErrTaskNotFound = errors.New("task not found")
+
 // Data setup
 type User struct {
 	Id   int    `json:"id"`
@@ -36,3 +42,12 @@ func getTasksByUserId(tasks *utils.KeyValueStore[int, Task], userId int) *utils.
 
 	return userTasks
 }
+
+func getUserTask(tasks *utils.KeyValueStore[int, Task], userId int, taskId int) (Task, error) {
+	task, exists := tasks.Get(taskId)
+	if !exists || task.UserId != userId {
+		return Task{}, fmt.Errorf("task %v of user %v: %w", taskId, userId, ErrTaskNotFound)
+	}
+
+	return task, nil
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/Fuad28/GOServe.git/goserve"
 	"github.com/Fuad28/GOServe.git/goserve/status"
 )
 
-func allTasks(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+// taskParams is bound from the request path by req.Bind
+type taskParams struct {
+	Id int `path:"id" valid:"required"`
+}
+
+func allTasks(req *goserve.Request, res goserve.IResponse) (goserve.IResponse, error) {
 	// userId exists because the route is protected on server level
 	userId, _ := req.Store.Get("userId")
 	userTasks := getTasksByUserId(tasks, userId.(int))
@@ -17,49 +19,35 @@ func allTasks(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
 		goserve.JSON{
 			"tasks": userTasks.GetAll(),
 		},
-	)
+	), nil
 }
 
-func taskDetails(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+func taskDetails(req *goserve.Request, res goserve.IResponse) (goserve.IResponse, error) {
 	userId, _ := req.Store.Get("userId")
 
-	taskIdStr, _ := req.PathParams().Get("id")
-	taskId, err := strconv.Atoi(taskIdStr)
+	// Validation errors are sent as a 422 response by the server's error handler.
+	var params taskParams
+	if err := req.Bind(&params); err != nil {
+		return nil, err
+	}
 
+	task, err := getUserTask(tasks, userId.(int), params.Id)
 	if err != nil {
-		return res.SetStatus(status.HTTP_400_BAD_REQUEST).Send(
-			goserve.JSON{
-				"error": "Invalid id",
-			},
-		)
+		return nil, err
 	}
 
-	userTasks := getTasksByUserId(tasks, userId.(int))
-	if task, exists := userTasks.Get(taskId); exists {
-		return res.SetStatus(status.HTTP_200_OK).Send(
-			goserve.JSON{
-				"task": task,
-			},
-		)
-
-	} else {
-		return res.SetStatus(status.HTTP_404_NOT_FOUND).Send(
-			goserve.JSON{
-				"error": "Not Found",
-			},
-		)
-	}
+	return res.SetStatus(status.HTTP_200_OK).Send(
+		goserve.JSON{
+			"task": task,
+		},
+	), nil
 }
 
-func createTask(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+func createTask(req *goserve.Request, res goserve.IResponse) (goserve.IResponse, error) {
 	var task Task
 
 	if err := req.Body(&task); err != nil {
-		return res.SetStatus(status.HTTP_400_BAD_REQUEST).Send(
-			goserve.JSON{
-				"error": fmt.Sprintf("invalid body: %v", err.Error()),
-			},
-		)
+		return nil, err
 	}
 
 	userId, _ := req.Store.Get("userId")
@@ -71,34 +59,23 @@ func createTask(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
 		goserve.JSON{
 			"task": task,
 		},
-	)
+	), nil
 }
 
-func deleteTask(req *goserve.Request, res goserve.IResponse) goserve.IResponse {
+func deleteTask(req *goserve.Request, res goserve.IResponse) (goserve.IResponse, error) {
 	userId, _ := req.Store.Get("userId")
 
-	taskIdStr, _ := req.PathParams().Get("id")
-	taskId, err := strconv.Atoi(taskIdStr)
-
-	if err != nil {
-		return res.SetStatus(status.HTTP_400_BAD_REQUEST).Send(
-			goserve.JSON{
-				"error": "Invalid id",
-			},
-		)
+	var params taskParams
+	if err := req.Bind(&params); err != nil {
+		return nil, err
 	}
 
-	userTasks := getTasksByUserId(tasks, userId.(int))
-	if task, exists := userTasks.Get(taskId); exists && task.UserId == userId {
-		tasks.Delete(taskId)
-		return res.SetStatus(status.HTTP_204_NO_CONTENT).Send(nil)
-
-	} else {
-		return res.SetStatus(status.HTTP_404_NOT_FOUND).Send(
-			goserve.JSON{
-				"error": "Not Found",
-			},
-		)
+	// ErrTaskNotFound is mapped to a 404 response in main.go
+	task, err := getUserTask(tasks, userId.(int), params.Id)
+	if err != nil {
+		return nil, err
 	}
 
+	tasks.Delete(task.Id)
+	return res.SetStatus(status.HTTP_204_NO_CONTENT).Send(nil), nil
 }
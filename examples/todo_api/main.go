@@ -1,7 +1,10 @@
 package main
 
 import (
+	"log"
+
 	"github.com/Fuad28/GOServe.git/goserve"
+	"github.com/Fuad28/GOServe.git/goserve/status"
 	"github.com/Fuad28/GOServe.git/goserve/utils"
 )
 
@@ -28,16 +31,22 @@ func main() {
 
 	// Add server level middlewares
 	server.AddMiddleWares(
+		goserve.AccessLogMiddleware(goserve.AccessLogConfig{Format: goserve.AccessLogCombined}),
 		goserve.CORSMiddleware(server.AllowedOrigins()),
 		authenticationMiddlware,
 	)
 
-	// Register routes
-	server.GET("/tasks", allTasks)
-	server.POST("/tasks", createTask)
-	server.GET("/tasks/:id", taskDetails)
-	server.DELETE("/tasks/:id", deleteTask)
+	// Map errors returned by the handlers to responses, even when wrapped.
+	server.MapError(ErrTaskNotFound, goserve.NewHTTPError(status.HTTP_404_NOT_FOUND, "task_not_found", "Not Found"))
+
+	// Register routes, the handlers return errors so they are adapted with goserve.WithError
+	server.GET("/tasks", goserve.WithError(allTasks))
+	server.POST("/tasks", goserve.WithError(createTask))
+	server.GET("/tasks/:id<int>", goserve.WithError(taskDetails))
+	server.DELETE("/tasks/:id<int>", goserve.WithError(deleteTask))
 
 	// Start server and listen for connections
-	server.StartAndListen()
+	if err := server.StartAndListen(); err != nil {
+		log.Fatal(err)
+	}
 }
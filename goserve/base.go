@@ -3,14 +3,19 @@ package goserve
 import (
 	"errors"
 	"net"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/Fuad28/GOServe.git/goserve/utils"
 )
 
 // Signature for route handlers
 type HandlerFunc func(*Request, IResponse) IResponse
 
+// Signature for the handler of requests that couldn't be read or parsed, err describes the failure.
+// The request only holds the client and server addresses.
+type ParseErrorHandlerFunc func(*Request, IResponse, error) IResponse
+
 // Allowed HTTP methods
 const (
 	get     = "GET"
@@ -23,14 +28,15 @@ const (
 )
 
 // Array of HTTP allowed httpMethods
+// The order is used when listing methods e.g in the Allow header.
 var httpMethods = []string{
 	get,
+	head,
 	post,
 	put,
 	patch,
 	delete,
 	options,
-	head,
 }
 
 func getServerIP() (string, error) {
@@ -47,47 +53,126 @@ func getServerIP() (string, error) {
 	return "", errors.New("no suitable IP address found")
 }
 
-// Utility function used to match request path with registered routes.
-// path: the request path gotten from the client.
-// route: one of registered routes.
-func matchRoute(path string, route string) (bool, *utils.KeyValueStore[string, string]) {
-	pathParams := utils.NewKeyValueStore[string, string]()
-
-	pathParts := strings.Split(path, "/")
-	routeParts := strings.Split(route, "/")
-
-	if len(pathParts) != len(routeParts) {
-		return false, pathParams
+// Utility function to clean a request path and split it into percent-decoded segments.
+// Empty and "." segments are dropped and ".." removes the previous segment, so //tasks and /tasks/../tasks both become [tasks].
+// Decoding happens per segment so an encoded slash (%2F) stays part of its segment.
+// It also reports whether the path ends with a slash, the root path "/" doesn't count as one.
+func cleanPath(rawPath string) ([]string, bool, error) {
+	// Absolute-form request targets e.g GET http://example.com/tasks HTTP/1.1
+	if strings.HasPrefix(rawPath, "http://") || strings.HasPrefix(rawPath, "https://") {
+		parsed, err := url.Parse(rawPath)
+		if err != nil {
+			return nil, false, err
+		}
+		rawPath = parsed.EscapedPath()
 	}
 
-	for idx, curPathPart := range pathParts {
-		curRoutePart := routeParts[idx]
+	segments := []string{}
+	rawSegments := strings.Split(rawPath, "/")
 
-		if strings.HasPrefix(curRoutePart, ":") {
-			paramName := curRoutePart[1:]
-			pathParams.Set(paramName, curPathPart)
-
-		} else if curRoutePart != curPathPart {
-			return false, pathParams
+	for _, rawSegment := range rawSegments {
+		segment, err := url.PathUnescape(rawSegment)
+		if err != nil {
+			return nil, false, err
 		}
 
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, segment)
+		}
 	}
 
-	return true, pathParams
+	lastSegment := rawSegments[len(rawSegments)-1]
+	trailingSlash := len(segments) > 0 && (lastSegment == "" || lastSegment == "." || lastSegment == "..")
+
+	return segments, trailingSlash, nil
 }
 
 // Utility function to parse query parametes as a key value store.
-func parseQueryParams(params string) *utils.KeyValueStore[string, string] {
-	queryParams := utils.NewKeyValueStore[string, string]()
+// Keys and values are percent-decoded, a key without a value e.g ?debug is stored with an empty value.
+func parseQueryParams(params string) *Params {
+	queryParams := NewParams()
 	parts := strings.Split(params, "&")
 
 	for _, param := range parts {
-		keyValueArr := strings.Split(param, "=")
-		queryParams.Set(keyValueArr[0], keyValueArr[1])
+		if param == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(param, "=")
+
+		if decodedKey, err := url.QueryUnescape(key); err == nil {
+			key = decodedKey
+		}
+		if decodedValue, err := url.QueryUnescape(value); err == nil {
+			value = decodedValue
+		}
+
+		queryParams.Set(key, value)
 	}
 	return queryParams
 }
 
+// A value of a header with quality values, and its quality.
+type qualityValue struct {
+	value   string
+	quality float64
+}
+
+// Utility function to parse headers with quality values e.g Accept-Language: fr-CH, fr;q=0.9, en;q=0.8
+// Returns the values ordered by preference, values with q=0 are left out.
+func parseQualityValues(header string) []string {
+	values := []string{}
+
+	for _, qualityValue := range parseQualityList(header) {
+		if qualityValue.quality > 0 {
+			values = append(values, qualityValue.value)
+		}
+	}
+
+	return values
+}
+
+// Utility function to parse headers with quality values, keeping the quality of each value.
+// Values are ordered by preference and include those with q=0, which mark values the client refuses.
+// Parameters other than q are dropped e.g text/html;level=1;q=0.5 gives text/html
+func parseQualityList(header string) []qualityValue {
+	qualityValues := []qualityValue{}
+
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.TrimSpace(value)
+
+		if value == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, qValue, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "q" {
+				if parsed, err := strconv.ParseFloat(qValue, 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+
+		qualityValues = append(qualityValues, qualityValue{value, quality})
+	}
+
+	sort.SliceStable(qualityValues, func(i, j int) bool {
+		return qualityValues[i].quality > qualityValues[j].quality
+	})
+
+	return qualityValues
+}
+
 // Holds the byte value of 1MB, expected to help with the MaxRequestSize field of the config struct
 const ONE_MB = 1024
 
@@ -0,0 +1,357 @@
+package goserve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	validator "github.com/asaskevich/govalidator"
+)
+
+// Struct tags understood by req.Bind, each names where the field value is read from.
+// Fields without any of these tags are filled from the JSON body using their json tag.
+const (
+	sourcePath   = "path"
+	sourceQuery  = "query"
+	sourceHeader = "header"
+	sourceCookie = "cookie"
+	sourceBody   = "body"
+)
+
+var bindingSources = []string{sourcePath, sourceQuery, sourceHeader, sourceCookie}
+
+// Layouts tried in order when binding a string to a time.Time field without a `format` tag.
+var bindingTimeLayouts = []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// Bind fills the struct pointed to by v from the request and validates it.
+// Fields are read from the source named by their tag: `path:"id"`, `query:"page"`, `header:"X-Tenant"` or `cookie:"session"`.
+// Other fields are decoded from the JSON body, which never sets fields tagged with another source.
+// `default:"10"` is used when the source has no value, or the body doesn't have the key, and `format:"2006-01-02"` sets the layout for time fields.
+// Once filled, the struct is validated using the `valid` tags (see github.com/asaskevich/govalidator).
+// All failing fields are returned together as ValidationErrors.
+func (req *Request) Bind(v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("bind: v must be a non-nil pointer to a struct")
+	}
+
+	// Defaults of body fields are set before the body is decoded, so they only apply to the keys it doesn't have,
+	// e.g {"done": false} sets Done to false even with `default:"true"`.
+	bindErrs := req.bindBodyDefaults(value.Elem())
+
+	if len(req.body) > 0 {
+		// The body mustn't set fields read from other sources, e.g {"Tenant": "..."} for a `header:"X-Tenant"` field,
+		// so their values are restored once it's decoded.
+		saved := reflect.New(value.Elem().Type()).Elem()
+		saved.Set(value.Elem())
+
+		if err := json.Unmarshal(req.body, v); err != nil {
+			bindErrs = append(bindErrs, req.jsonFieldError(err))
+		}
+
+		restoreSourceFields(value.Elem(), saved)
+	}
+
+	fieldSources := map[string]string{}
+	bindErrs = append(bindErrs, req.bindFields(value.Elem(), fieldSources)...)
+
+	failed := map[string]bool{}
+	for _, fieldErr := range bindErrs {
+		failed[fieldErr.Field] = true
+	}
+
+	var validationErrs ValidationErrors
+	if _, err := validator.ValidateStruct(v); err != nil {
+		validationErrs = req.validationFieldErrors(err, value.Elem().Type(), fieldSources)
+	}
+	validationErrs = append(validationErrs, req.customValidationErrors(value, "", fieldSources)...)
+
+	for _, fieldErr := range validationErrs {
+		// A field that failed conversion is left empty, there's no need to also report it as required.
+		if !failed[fieldErr.Field] {
+			bindErrs = append(bindErrs, fieldErr)
+		}
+	}
+
+	if len(bindErrs) > 0 {
+		return bindErrs
+	}
+
+	return nil
+}
+
+// bindFields walks the struct fields setting those tagged with a request source.
+// fieldSources records the name and source of each bound field for error reporting.
+func (req *Request) bindFields(structValue reflect.Value, fieldSources map[string]string) ValidationErrors {
+	var bindErrs ValidationErrors
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindErrs = append(bindErrs, req.bindFields(fieldValue, fieldSources)...)
+			continue
+		}
+
+		source, name := fieldSource(field)
+		fieldSources[field.Name] = source + ":" + name
+
+		// Body fields are decoded from the body, their defaults are set by bindBodyDefaults.
+		if source == sourceBody {
+			continue
+		}
+
+		raw, exists := req.sourceValue(source, name)
+		if !exists {
+			if defaultValue, hasDefault := field.Tag.Lookup("default"); hasDefault && fieldValue.IsZero() {
+				raw, exists = defaultValue, true
+			}
+		}
+
+		if !exists {
+			continue
+		}
+
+		if fieldErr := req.setBoundField(fieldValue, field, source, name, raw); fieldErr != nil {
+			bindErrs = append(bindErrs, *fieldErr)
+		}
+	}
+
+	return bindErrs
+}
+
+// bindBodyDefaults sets the `default` of the body fields that are still zero.
+func (req *Request) bindBodyDefaults(structValue reflect.Value) ValidationErrors {
+	var bindErrs ValidationErrors
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindErrs = append(bindErrs, req.bindBodyDefaults(fieldValue)...)
+			continue
+		}
+
+		source, name := fieldSource(field)
+		defaultValue, hasDefault := field.Tag.Lookup("default")
+
+		if source != sourceBody || !hasDefault || !fieldValue.IsZero() {
+			continue
+		}
+
+		if fieldErr := req.setBoundField(fieldValue, field, source, name, defaultValue); fieldErr != nil {
+			bindErrs = append(bindErrs, *fieldErr)
+		}
+	}
+
+	return bindErrs
+}
+
+// setBoundField converts raw to the type of the field and sets it, returning the FieldError to report if it can't be converted.
+func (req *Request) setBoundField(fieldValue reflect.Value, field reflect.StructField, source string, name string, raw string) *FieldError {
+	if err := setFieldValue(fieldValue, raw, field.Tag.Get("format")); err != nil {
+		return &FieldError{
+			Field:   name,
+			Source:  source,
+			Rule:    ruleType,
+			Message: req.validationMessage(ruleType, name, err.Error()),
+		}
+	}
+
+	return nil
+}
+
+// restoreSourceFields sets the fields tagged with a request source back to their value in saved.
+func restoreSourceFields(structValue reflect.Value, saved reflect.Value) {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			restoreSourceFields(structValue.Field(i), saved.Field(i))
+			continue
+		}
+
+		if source, _ := fieldSource(field); source != sourceBody {
+			structValue.Field(i).Set(saved.Field(i))
+		}
+	}
+}
+
+// fieldSource returns the source and the name a field is read by.
+func fieldSource(field reflect.StructField) (string, string) {
+	for _, source := range bindingSources {
+		if name, exists := field.Tag.Lookup(source); exists {
+			return source, name
+		}
+	}
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		name = field.Name
+	}
+
+	return sourceBody, name
+}
+
+// sourceValue looks up the raw value of name in the given request source.
+func (req *Request) sourceValue(source string, name string) (string, bool) {
+	switch source {
+	case sourcePath:
+		return req.PathParams().Get(name)
+
+	case sourceQuery:
+		return req.QueryParams().Get(name)
+
+	case sourceHeader:
+		return req.header(name)
+
+	case sourceCookie:
+		if cookie, exists := req.Cookie(name); exists {
+			return cookie.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// header does a case insensitive lookup of a request header as header names aren't case sensitive.
+func (req *Request) header(name string) (string, bool) {
+	if req.headers == nil {
+		return "", false
+	}
+
+	if value, exists := req.headers.Get(name); exists {
+		return value, true
+	}
+
+	for key, value := range req.headers.GetAll() {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// setFieldValue converts raw to the type of the field and sets it.
+// Slices are filled from comma separated values.
+func setFieldValue(field reflect.Value, raw string, format string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFieldValue(elem.Elem(), raw, format); err != nil {
+			return err
+		}
+		field.Set(elem)
+
+		return nil
+	}
+
+	switch field.Interface().(type) {
+	case time.Time:
+		parsed, err := parseBindingTime(raw, format)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+
+		return nil
+
+	case time.Duration:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid duration", raw)
+		}
+		field.SetInt(int64(parsed))
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid boolean", raw)
+		}
+		field.SetBool(parsed)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid integer", raw)
+		}
+		field.SetInt(parsed)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid unsigned integer", raw)
+		}
+		field.SetUint(parsed)
+
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid number", raw)
+		}
+		field.SetFloat(parsed)
+
+	case reflect.Slice:
+		parts := strings.Split(raw, ",")
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+
+		for idx, part := range parts {
+			if err := setFieldValue(slice.Index(idx), strings.TrimSpace(part), format); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+
+	return nil
+}
+
+func parseBindingTime(raw string, format string) (time.Time, error) {
+	if format != "" {
+		parsed, err := time.Parse(format, raw)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q does not match the time format %v", raw, format)
+		}
+		return parsed, nil
+	}
+
+	for _, layout := range bindingTimeLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not a valid time", raw)
+}
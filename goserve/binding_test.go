@@ -0,0 +1,121 @@
+package goserve
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type spoofedParams struct {
+	Id      int    `path:"id"`
+	Tenant  string `header:"X-Tenant"`
+	Session string `cookie:"session"`
+	Page    int    `query:"page" default:"1"`
+	Title   string `json:"title"`
+}
+
+func TestBindIgnoresBodyForSourceFields(t *testing.T) {
+	server := NewServer(Config{})
+
+	var params spoofedParams
+	var bindErr error
+	server.POST("/tasks/:id<int>", func(req *Request, res IResponse) IResponse {
+		bindErr = req.Bind(&params)
+		return res.Send(nil)
+	})
+
+	body := `{"Id": 99, "Tenant": "evil", "Session": "stolen", "Page": 7, "title": "write tests"}`
+	req, err := NewRequest(fmt.Sprintf("POST /tasks/5 HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n\r\n%v", body), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.HandleRequest(req)
+
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+
+	want := spoofedParams{Id: 5, Page: 1, Title: "write tests"}
+	if params != want {
+		t.Errorf("got %+v, want %+v", params, want)
+	}
+}
+
+type sluggedParams struct {
+	Slug string `json:"slug" valid:"slug"`
+}
+
+func TestAddValidatorIsScopedToTheServer(t *testing.T) {
+	isSlug := func(value any, parent any) bool { return !strings.ContainsAny(value.(string), " _") }
+
+	server, other := NewServer(Config{}), NewServer(Config{})
+	server.AddValidator("slug", isSlug)
+
+	var bindErr error
+	for _, s := range []*Server{server, other} {
+		s.POST("/pages", func(req *Request, res IResponse) IResponse {
+			bindErr = req.Bind(&sluggedParams{})
+			return res.Send(nil)
+		})
+	}
+
+	tests := []struct {
+		server *Server
+		slug   string
+		valid  bool
+	}{
+		{server, "a-slug", true},
+		{server, "not a slug", false},
+		{other, "a-slug", false},
+	}
+
+	for _, test := range tests {
+		sendRequest(t, test.server, "POST /pages HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n\r\n{\"slug\": \""+test.slug+"\"}")
+
+		var validationErrs ValidationErrors
+		failed := errors.As(bindErr, &validationErrs) && len(validationErrs) == 1 && validationErrs[0].Rule == "slug" && validationErrs[0].Field == "slug"
+
+		if failed == test.valid {
+			t.Errorf("got error %v for %q, want valid %v", bindErr, test.slug, test.valid)
+		}
+	}
+}
+
+type defaultedBody struct {
+	Done  bool   `json:"done" default:"true"`
+	Count int    `json:"count" default:"5"`
+	Title string `json:"title" default:"untitled"`
+}
+
+func TestBindDefaultsOnlyApplyToMissingBodyKeys(t *testing.T) {
+	server := NewServer(Config{})
+
+	var params defaultedBody
+	var bindErr error
+	server.POST("/tasks", func(req *Request, res IResponse) IResponse {
+		params = defaultedBody{}
+		bindErr = req.Bind(&params)
+		return res.Send(nil)
+	})
+
+	tests := []struct {
+		body string
+		want defaultedBody
+	}{
+		{`{"done": false, "count": 0}`, defaultedBody{Done: false, Count: 0, Title: "untitled"}},
+		{`{"title": ""}`, defaultedBody{Done: true, Count: 5, Title: ""}},
+		{``, defaultedBody{Done: true, Count: 5, Title: "untitled"}},
+	}
+
+	for _, test := range tests {
+		sendRequest(t, server, "POST /tasks HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n\r\n"+test.body)
+
+		if bindErr != nil {
+			t.Fatal(bindErr)
+		}
+		if params != test.want {
+			t.Errorf("got %+v for body %q, want %+v", params, test.body, test.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package goserve
+
+// handlerChain runs the middlewares and handler of a request in order, see req.Next()
+type handlerChain struct {
+	handlers []HandlerFunc
+
+	// Position of the handler running, -1 before the first one is run.
+	// The handler called by Next is the one after the caller's.
+	current int
+
+	// Responses returned by the handlers, by position, once they have returned.
+	results []handlerResult
+
+	// Set by req.Abort(), the remaining handlers are skipped.
+	aborted bool
+}
+
+type handlerResult struct {
+	response IResponse
+	returned bool
+}
+
+// runChain starts passing the request through the handlers.
+func (req *Request) runChain(handlers []HandlerFunc, res IResponse) IResponse {
+	req.handlerChain = &handlerChain{handlers: handlers, current: -1, results: make([]handlerResult, len(handlers))}
+
+	return req.Next(res)
+}
+
+// Next passes the request to the next handler of the chain and returns its response.
+// Once the chain is exhausted or aborted res is returned as is.
+// Calling Next more than once is safe: the next handler is only ever run once, later calls return the response it returned.
+// So a handler that returns without calling Next, e.g an authentication middleware answering 401, still stops the chain
+// when the middlewares before it call Next again.
+func (req *Request) Next(res IResponse) IResponse {
+	chain := req.handlerChain
+	if chain == nil || chain.aborted {
+		return res
+	}
+
+	caller := chain.current
+	position := caller + 1
+	if position >= len(chain.handlers) {
+		return res
+	}
+
+	if result := chain.results[position]; result.returned {
+		return result.response
+	}
+
+	chain.current = position
+	defer func() { chain.current = caller }()
+
+	response := chain.handlers[position](req, res)
+	chain.results[position] = handlerResult{response: response, returned: true}
+
+	return response
+}
+
+// Abort stops the chain, the handlers after the caller are skipped and Next returns the response as is.
+// The caller still returns the response to send e.g
+//
+//	if !authorized {
+//		req.Abort()
+//		return res.SetStatus(status.HTTP_401_UNAUTHORIZED).Send(goserve.JSON{"message": "unauthorized"})
+//	}
+func (req *Request) Abort() {
+	if req.handlerChain != nil {
+		req.handlerChain.aborted = true
+	}
+}
+
+// IsAborted reports whether Abort was called while handling the request.
+func (req *Request) IsAborted() bool {
+	return req.handlerChain != nil && req.handlerChain.aborted
+}
+
+// Route returns the route matched by the request, so middlewares can tell which route they run for e.g with route.Info()
+// It's nil when no route matched, e.g in the not found and method not allowed handlers.
+func (req *Request) Route() *Route {
+	return req.route
+}
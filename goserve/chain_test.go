@@ -0,0 +1,56 @@
+package goserve
+
+import (
+	"testing"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// retryingMiddleware calls Next twice, as middlewares retrying or inspecting the response may do.
+func retryingMiddleware(req *Request, res IResponse) IResponse {
+	req.Next(res)
+	return req.Next(res)
+}
+
+func TestNextDoesNotRunHandlersSkippedByAMiddleware(t *testing.T) {
+	server := NewServer(Config{})
+	handlerRuns := 0
+
+	authenticationMiddleware := func(req *Request, res IResponse) IResponse {
+		return res.SetStatus(status.HTTP_401_UNAUTHORIZED).Send("unauthorized")
+	}
+	server.GET("/secret", func(req *Request, res IResponse) IResponse {
+		handlerRuns++
+		return res.Send("secret")
+	}, retryingMiddleware, authenticationMiddleware)
+
+	res := sendRequest(t, server, "GET /secret HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	if res.StatusCode() != 401 || res.Body() != "unauthorized" {
+		t.Errorf("got status %v and body %v, want the 401 of the middleware", res.StatusCode(), res.Body())
+	}
+	if handlerRuns != 0 {
+		t.Errorf("got the handler run %v times, want it skipped", handlerRuns)
+	}
+}
+
+func TestNextRunsHandlersOnce(t *testing.T) {
+	server := NewServer(Config{})
+	middlewareRuns, handlerRuns := 0, 0
+
+	countingMiddleware := func(req *Request, res IResponse) IResponse {
+		middlewareRuns++
+		return req.Next(res)
+	}
+	server.GET("/tasks", func(req *Request, res IResponse) IResponse {
+		handlerRuns++
+		return res.Send("tasks")
+	}, retryingMiddleware, countingMiddleware)
+
+	res := sendRequest(t, server, "GET /tasks HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	if res.Body() != "tasks" {
+		t.Errorf("got body %v, want the handler's", res.Body())
+	}
+	if middlewareRuns != 1 || handlerRuns != 1 {
+		t.Errorf("got the middleware run %v times and the handler %v times, want once each", middlewareRuns, handlerRuns)
+	}
+}
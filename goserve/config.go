@@ -1,5 +1,24 @@
 package goserve
 
+import (
+	"log/slog"
+	"time"
+)
+
+// PathPolicy sets how request paths that only differ from a registered route by a trailing slash or case are handled.
+type PathPolicy int
+
+const (
+	// PathStrict doesn't match the route, the request gets a 404 response.
+	PathStrict PathPolicy = iota
+
+	// PathRedirect redirects to the registered path, with 301 for GET and HEAD requests and 308 for other methods.
+	PathRedirect
+
+	// PathMatch handles the request with the route as if the paths were the same.
+	PathMatch
+)
+
 // Config exposes the key parameters needed in creating a new server
 type Config struct {
 
@@ -11,4 +30,35 @@ type Config struct {
 
 	// Array of domains that are allowed when the CORS middleware inspects the request.
 	AllowedOrigins []string
+
+	// SecretKey is used to sign and encrypt cookies via SetSignedCookie and SetEncryptedCookie.
+	// Signed and encrypted cookies can't be used if it's not set.
+	SecretKey string
+
+	// HandlerTimeout sets a deadline on the context of every request, handlers should stop working once req.Context() is done.
+	// No deadline is set if it's 0.
+	HandlerTimeout time.Duration
+
+	// TrustedProxies lists the CIDRs or IPs of proxies in front of the server e.g []string{"10.0.0.0/8"}
+	// Forwarding headers are only used by req.RealIP(), req.Scheme() and req.ExternalHost() for requests coming from them.
+	TrustedProxies []string
+
+	// TrailingSlash sets how /tasks/ is handled when only /tasks is registered and vice versa, defaults to PathStrict.
+	TrailingSlash PathPolicy
+
+	// CaseSensitivity sets how /Tasks is handled when only /tasks is registered, defaults to PathStrict.
+	// Path parameter values are never changed.
+	CaseSensitivity PathPolicy
+
+	// StrictRoutes makes AddRoute panic instead of returning an error when a route can't be registered,
+	// e.g it's a duplicate or is ambiguous with a registered route, so mistakes are caught at start up.
+	StrictRoutes bool
+
+	// Debug adds details meant for development to responses, e.g the panic value and stack of 500 responses to recovered panics.
+	// It must not be set in production as it exposes the internals of the application.
+	Debug bool
+
+	// Logger receives the logs of the server e.g start up, panics and errors returned by handlers, defaults to slog.Default().
+	// Requests are logged by mounting AccessLogMiddleware.
+	Logger *slog.Logger
 }
@@ -0,0 +1,40 @@
+package goserve
+
+import "context"
+
+// ContextKey is a typed key for storing values in the request context.
+// It's a type-safe alternative to Request.Store as the value type is fixed when the key is created.
+//
+//	var userKey = goserve.NewContextKey[User]("user")
+//
+//	userKey.Set(req, user)          // in a middleware
+//	user, exists := userKey.Get(req) // in a handler
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey creates a key for values of type T, name is only used for debugging.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// Set stores value in the request context, it's visible to the rest of the handler chain.
+func (k *ContextKey[T]) Set(req *Request, value T) {
+	req.ctx = context.WithValue(req.Context(), k, value)
+}
+
+// Get retrieves the value stored under the key and whether it exists.
+func (k *ContextKey[T]) Get(req *Request) (T, bool) {
+	value, exists := req.Context().Value(k).(T)
+	return value, exists
+}
+
+// Value retrieves the value stored under the key from any context e.g one passed on to a database layer.
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	value, exists := ctx.Value(k).(T)
+	return value, exists
+}
+
+func (k *ContextKey[T]) String() string {
+	return "goserve context key " + k.name
+}
@@ -0,0 +1,303 @@
+package goserve
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned when reading cookies from a request, or setting signed and encrypted cookies on a response.
+var (
+	ErrCookieNotFound    = errors.New("cookie not found")
+	ErrInvalidCookie     = errors.New("invalid cookie value")
+	ErrInvalidCookieName = errors.New("invalid cookie name")
+	ErrMissingSecret     = errors.New("server secret key is not set")
+	ErrCookieTampered    = errors.New("cookie signature is invalid")
+	ErrCookieEncrypted   = errors.New("cookie could not be decrypted")
+)
+
+// Date format required for the Expires attribute.
+const cookieTimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// SameSite allows a server to define a cookie attribute making it impossible for
+// the browser to send this cookie along with cross-site requests.
+type SameSite string
+
+const (
+	SameSiteLax    SameSite = "Lax"
+	SameSiteStrict SameSite = "Strict"
+	SameSiteNone   SameSite = "None"
+)
+
+// Cookie represents an HTTP cookie as received in the Cookie header of a request
+// or sent in the Set-Cookie header of a response.
+// Only Name and Value are populated for cookies read from a request.
+type Cookie struct {
+	Name  string
+	Value string
+
+	// Path and Domain scope the cookie, both are omitted if empty.
+	Path   string
+	Domain string
+
+	// Expires is omitted if it's the zero time.
+	Expires time.Time
+
+	// MaxAge=0 means no Max-Age attribute is set.
+	// MaxAge<0 means delete the cookie now, equivalent to 'Max-Age: 0'.
+	// MaxAge>0 means the Max-Age attribute is present and given in seconds.
+	MaxAge int
+
+	Secure   bool
+	HttpOnly bool
+
+	// SameSite is omitted if empty.
+	SameSite SameSite
+
+	// Partitioned opts the cookie into partitioned storage (CHIPS), it requires Secure to be set.
+	Partitioned bool
+}
+
+// String returns the serialization of the cookie for use in a Set-Cookie response header.
+// Values holding spaces or commas are quoted, as done by net/http.
+// It's empty if the name isn't a valid token, the bytes of the value and path that can't be sent at all are dropped
+// and an invalid domain is omitted, so they can't add attributes or headers to the response.
+// res.SetCookie() logs the cookies that can't be sent as they are.
+func (c *Cookie) String() string {
+	if !isCookieNameValid(c.Name) {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString(c.Name + "=" + sanitizeCookieValue(c.Value))
+
+	if path := sanitizeCookiePath(c.Path); path != "" {
+		b.WriteString("; Path=" + path)
+	}
+	if domain := strings.TrimPrefix(c.Domain, "."); domain != "" && isCookieDomainValid(domain) {
+		b.WriteString("; Domain=" + domain)
+	}
+	if !c.Expires.IsZero() {
+		b.WriteString("; Expires=" + c.Expires.UTC().Format(cookieTimeFormat))
+	}
+	if c.MaxAge > 0 {
+		b.WriteString("; Max-Age=" + strconv.Itoa(c.MaxAge))
+	} else if c.MaxAge < 0 {
+		b.WriteString("; Max-Age=0")
+	}
+	if c.HttpOnly {
+		b.WriteString("; HttpOnly")
+	}
+	if c.Secure || c.Partitioned || c.SameSite == SameSiteNone {
+		b.WriteString("; Secure")
+	}
+	switch c.SameSite {
+	case SameSiteLax, SameSiteStrict, SameSiteNone:
+		b.WriteString("; SameSite=" + string(c.SameSite))
+	}
+	if c.Partitioned {
+		b.WriteString("; Partitioned")
+	}
+
+	return b.String()
+}
+
+// Cookie names are tokens (RFC 7230), they can't hold separators such as "=", ";" or spaces.
+func isCookieNameValid(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for idx := 0; idx < len(name); idx++ {
+		if !isTokenByte(name[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTokenByte(b byte) bool {
+	if b <= ' ' || b >= 0x7f {
+		return false
+	}
+	return !strings.ContainsRune(`()<>@,;:\"/[]?={}`, rune(b))
+}
+
+// Cookie values are made of cookie-octets (RFC 6265): printable ASCII characters except spaces, DQUOTE, commas, semicolons and backslashes.
+// Spaces and commas are accepted by browsers, they are sent by quoting the value.
+func isCookieValueByte(b byte) bool {
+	return b >= ' ' && b < 0x7f && b != '"' && b != ';' && b != '\\'
+}
+
+func isCookieValueValid(value string) bool {
+	for idx := 0; idx < len(value); idx++ {
+		if !isCookieValueByte(value[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Utility function dropping the bytes of a cookie value that can't be sent, and quoting it if it holds spaces or commas.
+func sanitizeCookieValue(value string) string {
+	value = sanitizeCookieBytes(value, isCookieValueByte)
+
+	if strings.ContainsAny(value, " ,") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// Utility function checking that a cookie is sent as set, e.g no byte of its value is dropped.
+func isCookieSendable(cookie Cookie) bool {
+	return isCookieNameValid(cookie.Name) && isCookieValueValid(cookie.Value) && sanitizeCookiePath(cookie.Path) == cookie.Path &&
+		(cookie.Domain == "" || isCookieDomainValid(strings.TrimPrefix(cookie.Domain, ".")))
+}
+
+// Utility function dropping the control characters, non ASCII bytes and semicolons of a cookie path.
+func sanitizeCookiePath(path string) string {
+	return sanitizeCookieBytes(path, func(b byte) bool { return b >= ' ' && b < 0x7f && b != ';' })
+}
+
+func sanitizeCookieBytes(value string, valid func(byte) bool) string {
+	sanitized := make([]byte, 0, len(value))
+
+	for idx := 0; idx < len(value); idx++ {
+		if valid(value[idx]) {
+			sanitized = append(sanitized, value[idx])
+		}
+	}
+	return string(sanitized)
+}
+
+// Cookie domains are host names: dot separated labels of letters, digits and hyphens, or an IP address.
+func isCookieDomainValid(domain string) bool {
+	if len(domain) > 255 {
+		return false
+	}
+	if net.ParseIP(domain) != nil {
+		return true
+	}
+
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+
+		for idx := 0; idx < len(label); idx++ {
+			b := label[idx]
+			if !('a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '-' || b == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Utility function to parse the Cookie header of a request into a list of cookies.
+// Malformed pairs are skipped rather than failing the whole header.
+func parseCookies(header string) []*Cookie {
+	cookies := []*Cookie{}
+
+	for _, part := range strings.Split(header, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+
+		if !found || name == "" {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) > 1 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+			value = value[1 : len(value)-1]
+		}
+
+		cookies = append(cookies, &Cookie{Name: name, Value: value})
+	}
+
+	return cookies
+}
+
+// Signed cookies hold the value and a HMAC-SHA256 signature of the name and value: value.signature
+func signCookieValue(secret []byte, name string, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(name + "=" + value))
+
+	return value + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func verifyCookieValue(secret []byte, name string, signedValue string) (string, error) {
+	idx := strings.LastIndex(signedValue, ".")
+	if idx < 0 {
+		return "", ErrInvalidCookie
+	}
+
+	value := signedValue[:idx]
+	if !hmac.Equal([]byte(signCookieValue(secret, name, value)), []byte(signedValue)) {
+		return "", ErrCookieTampered
+	}
+
+	return value, nil
+}
+
+// Encrypted cookies are sealed with AES-GCM using a key derived from the server secret.
+// The cookie name is used as additional data so a value can't be moved to another cookie.
+func encryptCookieValue(secret []byte, name string, value string) (string, error) {
+	gcm, err := cookieCipher(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func decryptCookieValue(secret []byte, name string, encryptedValue string) (string, error) {
+	gcm, err := cookieCipher(secret)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encryptedValue)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", ErrInvalidCookie
+	}
+
+	nonce, cipherText := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	value, err := gcm.Open(nil, nonce, cipherText, []byte(name))
+	if err != nil {
+		return "", ErrCookieEncrypted
+	}
+
+	return string(value), nil
+}
+
+func cookieCipher(secret []byte) (cipher.AEAD, error) {
+	key := sha256.Sum256(secret)
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,91 @@
+package goserve
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestCookieStringCannotInjectAttributes(t *testing.T) {
+	cookie := Cookie{
+		Name:     "session",
+		Value:    "x; Domain=evil.com\r\nX-Injected: 1",
+		Path:     "/; Secure\r\n",
+		Domain:   "evil.com\r\nX-Injected: 1",
+		SameSite: SameSite("Lax\r\nX-Injected: 1"),
+	}
+
+	serialized := cookie.String()
+	// The value and path lose the bytes that can't be sent, the invalid domain and SameSite are omitted.
+	if want := `session="x Domain=evil.comX-Injected: 1"; Path=/ Secure`; serialized != want {
+		t.Errorf("got %q, want %q", serialized, want)
+	}
+
+	if serialized := (&Cookie{Name: "a b", Value: "1"}).String(); serialized != "" {
+		t.Errorf("got %q for an invalid name, want nothing", serialized)
+	}
+	if serialized := (&Cookie{Name: "theme", Value: "dark mode, blue"}).String(); serialized != `theme="dark mode, blue"` {
+		t.Errorf("got %q, want the value quoted", serialized)
+	}
+}
+
+func TestSetCookieSkipsInvalidNames(t *testing.T) {
+	res := NewResponse(nil)
+	res.SetCookie(Cookie{Name: "bad\r\nX-Injected: 1", Value: "1"})
+	res.SetCookie(Cookie{Name: "theme", Value: "dark", Domain: ".example.com"})
+
+	headers := res.HeadersToString()
+	if strings.Contains(headers, "X-Injected") {
+		t.Errorf("got headers %q, want the cookie with an invalid name left out", headers)
+	}
+	if !strings.Contains(headers, "Set-Cookie:theme=dark; Domain=example.com\r\n") {
+		t.Errorf("got headers %q, want the valid cookie", headers)
+	}
+}
+
+func TestSetSignedCookieRejectsInvalidValues(t *testing.T) {
+	res := NewResponse(nil)
+	res.secretKey = []byte("secret")
+
+	if err := res.SetSignedCookie(Cookie{Name: "session", Value: "a;b"}); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("got error %v, want ErrInvalidCookie", err)
+	}
+	if err := res.SetSignedCookie(Cookie{Name: "a;b", Value: "1"}); !errors.Is(err, ErrInvalidCookieName) {
+		t.Errorf("got error %v, want ErrInvalidCookieName", err)
+	}
+	if len(res.Cookies()) != 0 {
+		t.Errorf("got cookies %v, want none", res.Cookies())
+	}
+}
+
+func TestSetCookieLogsChangedCookies(t *testing.T) {
+	var logs bytes.Buffer
+
+	server := NewServer(Config{Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+	server.GET("/theme", func(req *Request, res IResponse) IResponse {
+		res.SetCookie(Cookie{Name: "theme", Value: "dark mode"})
+		return res.SetCookie(Cookie{Name: "session", Value: "a;b"})
+	})
+
+	sendRequest(t, server, "GET /theme HTTP/1.1\r\nHost: localhost\r\n\r\n")
+
+	if strings.Contains(logs.String(), "cookie=theme") {
+		t.Errorf("got logs %q, want the quoted cookie sent without a warning", logs.String())
+	}
+	if !strings.Contains(logs.String(), "cookie=session") {
+		t.Errorf("got logs %q, want a warning for the cookie whose value is changed", logs.String())
+	}
+}
+
+func TestCookieHeaderIsCaseInsensitive(t *testing.T) {
+	req, err := NewRequest("GET / HTTP/1.1\r\nHost: localhost\r\ncookie: theme=dark\r\n\r\n", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cookie, exists := req.Cookie("theme"); !exists || cookie.Value != "dark" {
+		t.Errorf("got cookie %+v, want theme=dark from the lowercase header", cookie)
+	}
+}
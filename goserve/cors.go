@@ -0,0 +1,304 @@
+package goserve
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// CORSConfig configures the CORS middleware, see CORSMiddlewareWithConfig()
+type CORSConfig struct {
+
+	// AllowedOrigins lists the origins allowed to make cross-origin requests e.g https://example.com
+	// "*" allows any origin and a "*." prefix allows the subdomains of a host e.g https://*.example.com
+	AllowedOrigins []string
+
+	// AllowedOriginPatterns are regular expressions matched against the origin e.g ^https://pr-\d+\.preview\.example\.com$
+	AllowedOriginPatterns []*regexp.Regexp
+
+	// AllowOriginFunc decides for the origins that aren't allowed by AllowedOrigins and AllowedOriginPatterns.
+	AllowOriginFunc func(origin string, req *Request) bool
+
+	// AllowedMethods lists the methods allowed in preflight requests, defaults to GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowedMethods []string
+
+	// AllowedHeaders lists the headers allowed in preflight requests, defaults to Content-Type and Authorization.
+	// "*" allows any header.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists the response headers, besides the CORS-safelisted ones, that browsers let scripts read.
+	ExposedHeaders []string
+
+	// AllowCredentials lets browsers send cookies and Authorization headers with cross-origin requests.
+	// The origin is then always sent back instead of "*", as required by browsers.
+	AllowCredentials bool
+
+	// MaxAge is how long browsers can cache the result of a preflight request, it isn't sent when 0.
+	MaxAge time.Duration
+}
+
+// Methods and headers allowed when CORSConfig doesn't set them.
+var (
+	defaultCORSMethods = []string{get, head, post, put, patch, delete}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization"}
+)
+
+// corsPolicy is a CORSConfig prepared for handling requests.
+type corsPolicy struct {
+	config CORSConfig
+
+	// origins are lowercased and without trailing slashes, wildcardOrigins hold the scheme and host suffix of "*." origins
+	// e.g https and .example.com
+	origins         []string
+	wildcardOrigins []wildcardOrigin
+	anyOrigin       bool
+
+	anyHeader      bool
+	allowedHeaders []string
+}
+
+type wildcardOrigin struct {
+	scheme string
+	suffix string
+}
+
+func newCORSPolicy(config CORSConfig) *corsPolicy {
+	if len(config.AllowedMethods) == 0 {
+		config.AllowedMethods = defaultCORSMethods
+	}
+	if len(config.AllowedHeaders) == 0 {
+		config.AllowedHeaders = defaultCORSHeaders
+	}
+
+	policy := &corsPolicy{config: config}
+
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+
+		if origin == "*" {
+			policy.anyOrigin = true
+		} else if scheme, host, found := strings.Cut(origin, "://*."); found {
+			policy.wildcardOrigins = append(policy.wildcardOrigins, wildcardOrigin{scheme: scheme, suffix: "." + host})
+		} else {
+			policy.origins = append(policy.origins, origin)
+		}
+	}
+
+	for _, header := range config.AllowedHeaders {
+		if header == "*" {
+			policy.anyHeader = true
+		}
+		policy.allowedHeaders = append(policy.allowedHeaders, strings.ToLower(header))
+	}
+
+	return policy
+}
+
+// CORSMiddlewareWithConfig returns a middleware implementing CORS as configured e.g
+//
+//	server.AddMiddleWares(goserve.CORSMiddlewareWithConfig(goserve.CORSConfig{
+//		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.com"},
+//		AllowCredentials: true,
+//		MaxAge:           time.Hour,
+//	}))
+//
+// Requests without an Origin header and same origin requests aren't CORS requests, they are passed on untouched.
+// Cross-origin requests from origins that aren't allowed get a 403 response.
+// Preflight requests (OPTIONS requests with an Access-Control-Request-Method header) are answered with a 204 response
+// if the requested method and headers are allowed, and a 403 response otherwise.
+// Other cross-origin requests are passed on with the CORS headers set.
+// The config of routes set with route.CORS() replaces this one for their requests.
+func CORSMiddlewareWithConfig(config CORSConfig) HandlerFunc {
+	policy := newCORSPolicy(config)
+
+	return func(req *Request, res IResponse) IResponse {
+		return policy.forRoute(req).handle(req, res)
+	}
+}
+
+// CORS sets the CORS config of the route, replacing the one of the CORS middleware for its requests,
+// including the preflight requests sent before them e.g
+//
+//	route.CORS(goserve.CORSConfig{AllowedOrigins: []string{"*"}})
+//
+// It has no effect if no CORS middleware is mounted.
+func (route *Route) CORS(config CORSConfig) *Route {
+	defer route.lock()()
+
+	route.cors = newCORSPolicy(config)
+	return route
+}
+
+// forRoute returns the policy of the route the request is for, or the policy itself if the route doesn't have one.
+// Preflight requests are for the route of the method they ask for, not the OPTIONS route handling them.
+func (policy *corsPolicy) forRoute(req *Request) *corsPolicy {
+	route := req.route
+
+	if requestedMethod, isPreflight := preflightMethod(req); isPreflight && req.server != nil {
+		preflight := *req
+		preflight.method = strings.ToUpper(requestedMethod)
+		route = req.server.findRoute(&preflight).route
+	}
+
+	if route == nil || route.mu == nil {
+		return policy
+	}
+
+	route.mu.RLock()
+	defer route.mu.RUnlock()
+
+	if route.cors != nil {
+		return route.cors
+	}
+	return policy
+}
+
+// preflightMethod returns the method asked for by a preflight request, and whether the request is one.
+func preflightMethod(req *Request) (string, bool) {
+	if req.method != options || req.origin == nil {
+		return "", false
+	}
+
+	method, exists := req.header("Access-Control-Request-Method")
+	return method, exists && method != ""
+}
+
+func (policy *corsPolicy) handle(req *Request, res IResponse) IResponse {
+	// Responses depend on the Origin header, so caches must not serve them for other origins.
+	addVary(res, "Origin")
+
+	if req.origin == nil || isSameOrigin(req) {
+		return req.Next(res)
+	}
+
+	origin := req.origin.String()
+	if !policy.allowsOrigin(origin, req) {
+		return res.SetStatus(status.HTTP_403_FORBIDDEN).Send("Forbidden.")
+	}
+
+	requestedMethod, isPreflight := preflightMethod(req)
+	if !isPreflight {
+		policy.setOriginHeaders(res, origin)
+		if len(policy.config.ExposedHeaders) > 0 {
+			res.SetHeader("Access-Control-Expose-Headers", strings.Join(policy.config.ExposedHeaders, ", "))
+		}
+
+		return req.Next(res)
+	}
+
+	addVary(res, "Access-Control-Request-Method", "Access-Control-Request-Headers")
+
+	if !slices.Contains(policy.config.AllowedMethods, strings.ToUpper(requestedMethod)) {
+		return res.SetStatus(status.HTTP_403_FORBIDDEN).Send("Forbidden.")
+	}
+
+	requestedHeaders := requestedHeaders(req)
+	for _, header := range requestedHeaders {
+		if !policy.anyHeader && !slices.Contains(policy.allowedHeaders, strings.ToLower(header)) {
+			return res.SetStatus(status.HTTP_403_FORBIDDEN).Send("Forbidden.")
+		}
+	}
+
+	policy.setOriginHeaders(res, origin)
+	res.SetHeader("Access-Control-Allow-Methods", strings.Join(policy.config.AllowedMethods, ", "))
+
+	// "*" isn't a wildcard for requests with credentials, so the requested headers are sent back.
+	if policy.anyHeader && len(requestedHeaders) > 0 {
+		res.SetHeader("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+	} else if !policy.anyHeader {
+		res.SetHeader("Access-Control-Allow-Headers", strings.Join(policy.config.AllowedHeaders, ", "))
+	}
+
+	if policy.config.MaxAge > 0 {
+		res.SetHeader("Access-Control-Max-Age", strconv.Itoa(int(policy.config.MaxAge.Seconds())))
+	}
+
+	return res.SetStatus(status.HTTP_204_NO_CONTENT).Send(nil)
+}
+
+// setOriginHeaders sets the headers allowing the origin to read the response.
+func (policy *corsPolicy) setOriginHeaders(res IResponse, origin string) {
+	if policy.anyOrigin && !policy.config.AllowCredentials {
+		res.SetHeader("Access-Control-Allow-Origin", "*")
+	} else {
+		res.SetHeader("Access-Control-Allow-Origin", origin)
+	}
+
+	if policy.config.AllowCredentials {
+		res.SetHeader("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowsOrigin checks the origin against the origin lists, the patterns, then the predicate.
+func (policy *corsPolicy) allowsOrigin(origin string, req *Request) bool {
+	if policy.anyOrigin {
+		return true
+	}
+
+	normalized := strings.ToLower(origin)
+	if slices.Contains(policy.origins, normalized) {
+		return true
+	}
+
+	if parsed, err := url.Parse(normalized); err == nil {
+		for _, wildcard := range policy.wildcardOrigins {
+			if parsed.Scheme == wildcard.scheme && strings.HasSuffix(parsed.Host, wildcard.suffix) {
+				return true
+			}
+		}
+	}
+
+	for _, pattern := range policy.config.AllowedOriginPatterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+
+	return policy.config.AllowOriginFunc != nil && policy.config.AllowOriginFunc(origin, req)
+}
+
+// isSameOrigin checks whether the Origin header is the origin of the server,
+// the scheme and host are resolved through trusted proxies (see Config.TrustedProxies).
+func isSameOrigin(req *Request) bool {
+	return (req.origin.Scheme == req.Scheme()) && strings.EqualFold(req.origin.Host, req.ExternalHost())
+}
+
+// requestedHeaders returns the headers listed in the Access-Control-Request-Headers header of a preflight request.
+func requestedHeaders(req *Request) []string {
+	headers := []string{}
+
+	value, _ := req.header("Access-Control-Request-Headers")
+	for _, header := range strings.Split(value, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+
+	return headers
+}
+
+// addVary adds the headers to the Vary header of the response, keeping the ones already listed.
+func addVary(res IResponse, headers ...string) {
+	vary, _ := res.Headers().Get("Vary")
+
+	for _, header := range headers {
+		listed := slices.ContainsFunc(strings.Split(vary, ","), func(value string) bool {
+			return strings.EqualFold(strings.TrimSpace(value), header)
+		})
+
+		if listed {
+			continue
+		}
+		if vary != "" {
+			vary += ", "
+		}
+		vary += header
+	}
+
+	res.SetHeader("Vary", vary)
+}
@@ -0,0 +1,154 @@
+package goserve
+
+import (
+	"testing"
+)
+
+func corsServer(config CORSConfig) *Server {
+	server := NewServer(Config{})
+	server.AddMiddleWares(CORSMiddlewareWithConfig(config))
+	server.GET("/tasks", func(req *Request, res IResponse) IResponse { return res.Send("tasks") })
+	server.PUT("/tasks", func(req *Request, res IResponse) IResponse { return res.Send("updated") })
+
+	return server
+}
+
+func TestCORSPassesNonCORSRequestsThrough(t *testing.T) {
+	server := corsServer(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})
+
+	tests := []struct {
+		name    string
+		headers string
+	}{
+		{"no Origin header", ""},
+		{"same origin", "Origin: http://localhost:8000\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := sendRequest(t, server, "GET /tasks HTTP/1.1\r\nHost: localhost:8000\r\n"+test.headers+"\r\n")
+
+			if res.Body() != "tasks" {
+				t.Errorf("got status %v and body %v, want the request passed on", res.StatusCode(), res.Body())
+			}
+			if origin, exists := res.Headers().Get("Access-Control-Allow-Origin"); exists {
+				t.Errorf("got Access-Control-Allow-Origin %q, want none", origin)
+			}
+			if vary, _ := res.Headers().Get("Vary"); vary != "Origin" {
+				t.Errorf("got Vary %q, want Origin", vary)
+			}
+		})
+	}
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	server := corsServer(CORSConfig{AllowedOrigins: []string{"https://app.example.com", "https://*.example.com"}})
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://api.example.com", true},
+		{"https://deep.api.example.com", true},
+		{"https://evilexample.com", false},
+		{"https://example.com.evil.com", false},
+		{"http://api.example.com", false},
+		{"https://example.com", false},
+	}
+
+	for _, test := range tests {
+		res := sendRequest(t, server, "GET /tasks HTTP/1.1\r\nHost: localhost\r\nOrigin: "+test.origin+"\r\n\r\n")
+		origin, _ := res.Headers().Get("Access-Control-Allow-Origin")
+
+		if test.allowed && (res.Body() != "tasks" || origin != test.origin) {
+			t.Errorf("got status %v and Access-Control-Allow-Origin %q for %v, want it allowed", res.StatusCode(), origin, test.origin)
+		}
+		if !test.allowed && (res.StatusCode() != 403 || origin != "") {
+			t.Errorf("got status %v and Access-Control-Allow-Origin %q for %v, want a 403", res.StatusCode(), origin, test.origin)
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	server := corsServer(CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"Content-Type"},
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		headers string
+		status  int
+	}{
+		{"allowed method and headers", "PUT", "content-type", 204},
+		{"method not allowed", "DELETE", "", 403},
+		{"header not allowed", "PUT", "Content-Type, X-Custom", 403},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			raw := "OPTIONS /tasks HTTP/1.1\r\nHost: localhost\r\nOrigin: https://app.example.com\r\nAccess-Control-Request-Method: " + test.method + "\r\n"
+			if test.headers != "" {
+				raw += "Access-Control-Request-Headers: " + test.headers + "\r\n"
+			}
+
+			res := sendRequest(t, server, raw+"\r\n")
+			if res.StatusCode() != test.status {
+				t.Errorf("got status %v, want %v", res.StatusCode(), test.status)
+			}
+
+			methods, _ := res.Headers().Get("Access-Control-Allow-Methods")
+			if allowed := test.status == 204; allowed != (methods == "GET, PUT") {
+				t.Errorf("got Access-Control-Allow-Methods %q", methods)
+			}
+		})
+	}
+}
+
+func TestCORSCredentialsWithAnyOrigin(t *testing.T) {
+	server := corsServer(CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+	res := sendRequest(t, server, "GET /tasks HTTP/1.1\r\nHost: localhost\r\nOrigin: https://app.example.com\r\n\r\n")
+
+	if origin, _ := res.Headers().Get("Access-Control-Allow-Origin"); origin != "https://app.example.com" {
+		t.Errorf("got Access-Control-Allow-Origin %q, want the request's origin", origin)
+	}
+	if credentials, _ := res.Headers().Get("Access-Control-Allow-Credentials"); credentials != "true" {
+		t.Errorf("got Access-Control-Allow-Credentials %q, want true", credentials)
+	}
+
+	server = corsServer(CORSConfig{AllowedOrigins: []string{"*"}})
+	res = sendRequest(t, server, "GET /tasks HTTP/1.1\r\nHost: localhost\r\nOrigin: https://app.example.com\r\n\r\n")
+
+	if origin, _ := res.Headers().Get("Access-Control-Allow-Origin"); origin != "*" {
+		t.Errorf("got Access-Control-Allow-Origin %q without credentials, want *", origin)
+	}
+}
+
+func TestRouteCORSOverridesPreflightOnDefaultOptionsRoute(t *testing.T) {
+	server := NewServer(Config{})
+	server.AddMiddleWares(CORSMiddlewareWithConfig(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}))
+
+	server.GET("/tasks", func(req *Request, res IResponse) IResponse { return res.Send("tasks") })
+	route, _ := server.PUT("/public", func(req *Request, res IResponse) IResponse { return res.Send("updated") })
+	route.CORS(CORSConfig{AllowedOrigins: []string{"https://partner.example.org"}, AllowedMethods: []string{"PUT"}})
+
+	preflight := func(path string) IResponse {
+		return sendRequest(t, server, "OPTIONS "+path+" HTTP/1.1\r\nHost: localhost\r\nOrigin: https://partner.example.org\r\nAccess-Control-Request-Method: PUT\r\n\r\n")
+	}
+
+	res := preflight("/public")
+	if origin, _ := res.Headers().Get("Access-Control-Allow-Origin"); res.StatusCode() != 204 || origin != "https://partner.example.org" {
+		t.Errorf("got status %v and Access-Control-Allow-Origin %q, want the route's config applied", res.StatusCode(), origin)
+	}
+	if methods, _ := res.Headers().Get("Access-Control-Allow-Methods"); methods != "PUT" {
+		t.Errorf("got Access-Control-Allow-Methods %q, want the route's methods", methods)
+	}
+
+	if res := preflight("/tasks"); res.StatusCode() != 403 {
+		t.Errorf("got status %v for a route without its own config, want the middleware's 403", res.StatusCode())
+	}
+}
@@ -0,0 +1,172 @@
+package goserve
+
+import (
+	"errors"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// Signature for route handlers returning errors instead of building error responses themselves.
+// Use WithError() to register them as a HandlerFunc, returned errors are turned into responses by the server's error handler.
+type ErrorHandlerFunc func(*Request, IResponse) (IResponse, error)
+
+// Signature for the server level handler turning errors returned by handlers into responses.
+type ErrorResponderFunc func(*Request, IResponse, error) IResponse
+
+// HTTPError is an error carrying the response to send for it, e.g
+//
+//	return nil, goserve.NewHTTPError(status.HTTP_404_NOT_FOUND, "task_not_found", "task not found")
+//
+// The response body has the shape {"error": message, "code": code, "details": details}, code and details are left out when empty.
+type HTTPError struct {
+	StatusCode int
+
+	// Code is a machine readable identifier of the error e.g task_not_found
+	Code string
+
+	// Message is a human readable description of the error, the status text is used when it's empty.
+	Message string
+
+	// Details holds any extra data about the error e.g the conflicting resource, it is encoded as JSON.
+	Details any
+
+	// Err is the underlying error, it isn't sent to the client.
+	Err error
+}
+
+// NewHTTPError returns an HTTPError, use WithDetails() and Wrap() to add details and the underlying error.
+func NewHTTPError(statusCode int, code string, message string) *HTTPError {
+	return &HTTPError{StatusCode: statusCode, Code: code, Message: message}
+}
+
+// WithDetails returns a copy of the error with the details set, so sentinel HTTPErrors can be shared.
+func (e *HTTPError) WithDetails(details any) *HTTPError {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Wrap returns a copy of the error wrapping err, so errors.Is() and errors.As() see through it.
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+func (e *HTTPError) Error() string {
+	message := e.message()
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+
+	return message
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+func (e *HTTPError) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return status.HTTPStatuses[e.StatusCode].Message
+}
+
+// send writes the error as the response.
+func (e *HTTPError) send(res IResponse) IResponse {
+	body := JSON{"error": e.message()}
+
+	if e.Code != "" {
+		body["code"] = e.Code
+	}
+	if e.Details != nil {
+		body["details"] = e.Details
+	}
+
+	return res.SetStatus(e.StatusCode).Send(body)
+}
+
+// errorMapping maps errors matching target with errors.Is() to an HTTPError, see server.MapError()
+type errorMapping struct {
+	target    error
+	httpError *HTTPError
+}
+
+// WithError adapts an ErrorHandlerFunc to a HandlerFunc, so it can be registered as a route handler or middleware e.g
+//
+//	server.GET("/tasks/:id", goserve.WithError(taskDetails))
+//
+// Returned errors are passed to the error handler of the server, see server.OnError()
+func WithError(handler ErrorHandlerFunc) HandlerFunc {
+	return func(req *Request, res IResponse) IResponse {
+		response, err := handler(req, res)
+		if err == nil {
+			return response
+		}
+
+		return req.server.handleError(req, res, err)
+	}
+}
+
+// OnError sets the handler turning errors returned by ErrorHandlerFuncs into responses, replacing the default mapping:
+//   - HTTPErrors, even wrapped, are sent as they are.
+//   - errors matching one registered with MapError() are sent as the HTTPError it's mapped to.
+//   - ValidationErrors are sent with SendValidationErrors()
+//   - other errors are logged and get a 500 response, their message isn't sent to the client.
+func (s *Server) OnError(handler ErrorResponderFunc) {
+	s.errorHandler = handler
+}
+
+// MapError makes the default error handler send httpError for errors matching target with errors.Is(),
+// so sentinel errors of the application don't need to know about HTTP e.g
+//
+//	server.MapError(ErrTaskNotFound, goserve.NewHTTPError(status.HTTP_404_NOT_FOUND, "task_not_found", "task not found"))
+//
+// Mappings are checked in the order they are registered.
+func (s *Server) MapError(target error, httpError *HTTPError) {
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+
+	s.errorMappings = append(s.errorMappings, errorMapping{target: target, httpError: httpError})
+}
+
+// handleError turns an error returned by a handler into a response, using the error handler set with OnError() if any.
+func (s *Server) handleError(req *Request, res IResponse, err error) IResponse {
+	if s == nil {
+		return defaultErrorHandler(req, res, err, nil)
+	}
+
+	if s.errorHandler != nil {
+		return s.errorHandler(req, res, err)
+	}
+
+	s.routesMu.RLock()
+	mappings := s.errorMappings
+	s.routesMu.RUnlock()
+
+	return defaultErrorHandler(req, res, err, mappings)
+}
+
+// Default handler used when OnError() isn't set.
+func defaultErrorHandler(req *Request, res IResponse, err error, mappings []errorMapping) IResponse {
+	var httpError *HTTPError
+	if errors.As(err, &httpError) {
+		return httpError.send(res)
+	}
+
+	for _, mapping := range mappings {
+		if errors.Is(err, mapping.target) {
+			return mapping.httpError.send(res)
+		}
+	}
+
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return SendValidationErrors(res, validationErrs)
+	}
+
+	req.server.Logger().Error("handler returned an error", "method", req.method, "path", req.path, "error", err)
+	return res.SetStatus(status.HTTP_500_INTERNAL_SERVER_ERROR).Send(JSON{"error": "Internal server error."})
+}
@@ -0,0 +1,180 @@
+package goserve
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Router groups routes under a shared path prefix and middlewares.
+// Groups are created from the server or another router with Group(), and register their routes on it directly:
+//
+//	api := server.Group("/api/v1", authenticationMiddlware)
+//	api.GET("/tasks", allTasks) // GET /api/v1/tasks
+//
+// A router can also be built on its own with NewRouter() and mounted under a prefix later with Mount().
+// Group middlewares run after the server middlewares and before the route middlewares.
+type Router struct {
+
+	// Path prepended to the routes registered on the router.
+	prefix string
+
+	// Middlewares prepended to the middlewares of the routes registered on the router.
+	middleWares []HandlerFunc
+
+	// register adds a route to what the router is attached to (the server or a parent router).
+	// It's nil for routers created with NewRouter() until they are mounted.
+	register registerFunc
+
+	// Routes added to a router before it's mounted, registered as they are when Mount() is called
+	// so what was set on them (name, matchers, CORS config, OpenAPI details...) is kept.
+	pending []*Route
+}
+
+// registerFunc adds a route built with NewRoute() to what a router is attached to, and returns it once registered.
+// The path and middlewares of the route already include those of the router.
+type registerFunc func(route *Route) (*Route, error)
+
+// registerRoute registers a route built with NewRoute() on the server, for any host.
+func (s *Server) registerRoute(route *Route) (*Route, error) {
+	return s.addHostRoute("", route)
+}
+
+// NewRouter creates a router that isn't attached to a server.
+// Its routes are registered once it's mounted with server.Mount() or router.Mount().
+func NewRouter(middlewares ...HandlerFunc) *Router {
+	return &Router{middleWares: middlewares}
+}
+
+// Group creates a group of routes under prefix on the server, the middlewares run for all routes of the group.
+func (s *Server) Group(prefix string, middlewares ...HandlerFunc) *Router {
+	return &Router{
+		prefix:      prefix,
+		middleWares: middlewares,
+		register:    s.registerRoute,
+	}
+}
+
+// Mount registers the routes of a router built with NewRouter() under prefix.
+// Routes added to the router after it's mounted are registered as well.
+func (s *Server) Mount(prefix string, router *Router) error {
+	return router.mount(prefix, s.registerRoute)
+}
+
+// Group creates a nested group of routes, its prefix and middlewares are added to the router's.
+func (r *Router) Group(prefix string, middlewares ...HandlerFunc) *Router {
+	return &Router{
+		prefix:      prefix,
+		middleWares: middlewares,
+		register:    r.addRoute,
+	}
+}
+
+// Mount registers the routes of a router built with NewRouter() under prefix, relative to the router's own prefix.
+func (r *Router) Mount(prefix string, router *Router) error {
+	return router.mount(prefix, r.addRoute)
+}
+
+func (r *Router) mount(prefix string, register registerFunc) error {
+	if r.register != nil {
+		return errors.New("router is already mounted")
+	}
+
+	r.register = func(route *Route) (*Route, error) {
+		route.setPath(joinPaths(prefix, route.path))
+		return register(route)
+	}
+
+	for _, route := range r.pending {
+		if _, err := r.register(route); err != nil {
+			return err
+		}
+	}
+	r.pending = nil
+
+	return nil
+}
+
+// AddRoute is used to register routes on the router, the router's prefix and middlewares are added to the route's.
+func (r *Router) AddRoute(path string, method string, handler HandlerFunc, middlewares []HandlerFunc) (*Route, error) {
+	return r.addRoute(NewRoute(path, method, handler, middlewares))
+}
+
+// addRoute adds the router's prefix and middlewares to the route's, then registers it or keeps it until the router is mounted.
+func (r *Router) addRoute(route *Route) (*Route, error) {
+	route.setPath(joinPaths(r.prefix, route.path))
+	route.middleWares = slices.Concat(r.middleWares, route.middleWares)
+
+	if r.register != nil {
+		return r.register(route)
+	}
+
+	if !slices.Contains(httpMethods, route.method) {
+		return nil, errors.New("invalid method")
+	}
+
+	r.pending = append(r.pending, route)
+
+	return route, nil
+}
+
+// Prefix returns the path prefix of the router, relative to what it's attached to.
+func (r *Router) Prefix() string {
+	return r.prefix
+}
+
+// MiddleWares returns the middlewares mounted on the router.
+func (r *Router) MiddleWares() []HandlerFunc {
+	return r.middleWares
+}
+
+// GET is shortcut for r.AddRoute(path, get, handler, middlewares)
+func (r *Router) GET(path string, handler HandlerFunc, middlewares ...HandlerFunc) (*Route, error) {
+	return r.AddRoute(path, get, handler, middlewares)
+}
+
+// POST is shortcut for r.AddRoute(path, post, handler, middlewares)
+func (r *Router) POST(path string, handler HandlerFunc, middlewares ...HandlerFunc) (*Route, error) {
+	return r.AddRoute(path, post, handler, middlewares)
+}
+
+// PATCH is shortcut for r.AddRoute(path, patch, handler, middlewares)
+func (r *Router) PATCH(path string, handler HandlerFunc, middlewares ...HandlerFunc) (*Route, error) {
+	return r.AddRoute(path, patch, handler, middlewares)
+}
+
+// PUT is shortcut for r.AddRoute(path, put, handler, middlewares)
+func (r *Router) PUT(path string, handler HandlerFunc, middlewares ...HandlerFunc) (*Route, error) {
+	return r.AddRoute(path, put, handler, middlewares)
+}
+
+// DELETE is shortcut for r.AddRoute(path, delete, handler, middlewares)
+func (r *Router) DELETE(path string, handler HandlerFunc, middlewares ...HandlerFunc) (*Route, error) {
+	return r.AddRoute(path, delete, handler, middlewares)
+}
+
+// OPTIONS is shortcut for r.AddRoute(path, options, handler, middlewares)
+func (r *Router) OPTIONS(path string, handler HandlerFunc, middlewares ...HandlerFunc) (*Route, error) {
+	return r.AddRoute(path, options, handler, middlewares)
+}
+
+// HEAD is shortcut for r.AddRoute(path, head, handler, middlewares)
+// The HEADMiddleware is automatically appended to this method to set the body to nil as required by HTTP spec
+func (r *Router) HEAD(path string, handler HandlerFunc, middlewares ...HandlerFunc) (*Route, error) {
+	return r.AddRoute(path, head, handler, append(middlewares, HEADMiddleware))
+}
+
+// Utility function to join a prefix and a path, e.g joinPaths("/api/", "/tasks") returns /api/tasks
+// A path of "" or "/" refers to the prefix itself.
+func joinPaths(prefix string, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	if path == "" || path == "/" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+
+	return prefix + "/" + strings.TrimPrefix(path, "/")
+}
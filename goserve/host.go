@@ -0,0 +1,184 @@
+package goserve
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Routes can be bound to a host with server.Host(), they are then only matched for requests to that host:
+//
+//	api.example.com          exact host
+//	*.example.com            any single subdomain e.g eu.example.com, but not example.com or a.eu.example.com
+//	:tenant.example.com      any single subdomain, captured as the path parameter "tenant"
+//
+// Hosts are matched against the hostname of req.Host(), ignoring case and the port.
+// Exact hosts are tried first, then the other patterns in registration order.
+// Routes without a host are matched for any host, after the routes of the matching hosts.
+
+// Kinds of host patterns.
+const (
+	exactHost = iota
+	wildcardHost
+	paramHost
+)
+
+// hostRoutes holds the routes bound to a host pattern.
+type hostRoutes struct {
+	pattern string
+	kind    int
+
+	// The whole host for exact patterns, the part following the subdomain otherwise e.g .example.com
+	suffix string
+
+	// Name the subdomain is captured under for :param patterns.
+	paramName string
+
+	// Radix trees of the routes per method.
+	trees map[string]*node
+}
+
+// routeTable is a set of trees matched by a request, with the parameters captured from its host.
+type routeTable struct {
+	trees      map[string]*node
+	hostParams []pathParam
+}
+
+// Host creates a group of routes only matched for requests to hosts matching pattern, see host.go for the syntax.
+// The middlewares run for all routes of the group, e.g
+//
+//	admin := server.Host("admin.example.com", adminAuthMiddleware)
+//	admin.GET("/users", allUsers)
+func (s *Server) Host(pattern string, middlewares ...HandlerFunc) *Router {
+	return &Router{
+		middleWares: middlewares,
+		register: func(route *Route) (*Route, error) {
+			return s.addHostRoute(pattern, route)
+		},
+	}
+}
+
+// Utility function to parse a host pattern, the port is ignored.
+func parseHostPattern(pattern string) (*hostRoutes, error) {
+	host := &hostRoutes{pattern: pattern, kind: exactHost}
+
+	hostname := strings.ToLower(pattern)
+	if idx := strings.LastIndexByte(hostname, ':'); idx > 0 && isPort(hostname[idx+1:]) {
+		hostname = hostname[:idx]
+	}
+
+	if hostname == "" {
+		return nil, fmt.Errorf("invalid host %q: it's empty", pattern)
+	}
+
+	subdomain, suffix, found := strings.Cut(hostname, ".")
+
+	switch {
+	case subdomain == "*":
+		host.kind = wildcardHost
+
+	case strings.HasPrefix(subdomain, ":"):
+		host.kind = paramHost
+		host.paramName = subdomain[1:]
+
+		if host.paramName == "" {
+			return nil, fmt.Errorf("invalid host %q: parameters must be named", pattern)
+		}
+
+	default:
+		if strings.ContainsAny(hostname, "*:") {
+			return nil, fmt.Errorf("invalid host %q: only the first label can be * or a parameter", pattern)
+		}
+
+		host.suffix = hostname
+		return host, nil
+	}
+
+	if !found || suffix == "" || strings.ContainsAny(suffix, "*:") {
+		return nil, fmt.Errorf("invalid host %q: only the first label can be * or a parameter", pattern)
+	}
+	host.suffix = "." + suffix
+
+	return host, nil
+}
+
+// match checks if the lowercased hostname matches the pattern and returns the parameter captured from it.
+func (host *hostRoutes) match(hostname string) ([]pathParam, bool) {
+	if host.kind == exactHost {
+		return nil, hostname == host.suffix
+	}
+
+	subdomain, found := strings.CutSuffix(hostname, host.suffix)
+	if !found || subdomain == "" || strings.Contains(subdomain, ".") {
+		return nil, false
+	}
+
+	if host.kind == paramHost {
+		return []pathParam{{key: host.paramName, value: subdomain}}, true
+	}
+
+	return nil, true
+}
+
+// hostRoutes returns the routes bound to the host pattern, adding them if needed.
+// Exact hosts are kept before the others so they are tried first.
+func (s *Server) hostRoutes(pattern string) (*hostRoutes, error) {
+	for _, host := range s.hosts {
+		if host.pattern == pattern {
+			return host, nil
+		}
+	}
+
+	host, err := parseHostPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	host.trees = map[string]*node{}
+
+	for _, other := range s.hosts {
+		if host.kind == paramHost && other.kind == paramHost && host.suffix == other.suffix {
+			return nil, fmt.Errorf("%w: host %v is ambiguous with host %v, parameters must have the same name", ErrRouteConflict, pattern, other.pattern)
+		}
+	}
+
+	position := len(s.hosts)
+	if host.kind == exactHost {
+		position = slices.IndexFunc(s.hosts, func(other *hostRoutes) bool { return other.kind != exactHost })
+		if position < 0 {
+			position = len(s.hosts)
+		}
+	}
+	s.hosts = slices.Insert(s.hosts, position, host)
+
+	return host, nil
+}
+
+// routeTables appends the trees to match the request with to tables: those of the hosts matching it then the routes without a host.
+func (s *Server) routeTables(req *Request, tables []routeTable) []routeTable {
+
+	if req.host != nil && len(s.hosts) > 0 {
+		hostname := strings.ToLower(req.host.Hostname())
+
+		for _, host := range s.hosts {
+			if hostParams, matched := host.match(hostname); matched {
+				tables = append(tables, routeTable{trees: host.trees, hostParams: hostParams})
+			}
+		}
+	}
+
+	return append(tables, routeTable{trees: s.trees})
+}
+
+func isPort(port string) bool {
+	if port == "" {
+		return false
+	}
+
+	for idx := 0; idx < len(port); idx++ {
+		if port[idx] < '0' || port[idx] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,147 @@
+package goserve
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// RouteInfo describes a registered route, see route.Info() and server.RouteTable().
+type RouteInfo struct {
+	Method string `json:"method"`
+
+	// The path as registered, including group prefixes.
+	Path string `json:"path"`
+
+	// The host pattern the route is bound to, empty if it's matched for any host.
+	Host string `json:"host,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	// Names of the handler and route middlewares (group middlewares included) e.g main.allTasks
+	Handler     string   `json:"handler"`
+	MiddleWares []string `json:"middlewares"`
+
+	// Where the route was registered, outside of goserve.
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	// Set for routes disabled with server.DisableRoute()
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Info returns the metadata of the route.
+func (route *Route) Info() RouteInfo {
+	if route.mu != nil {
+		route.mu.RLock()
+		defer route.mu.RUnlock()
+	}
+
+	return route.info()
+}
+
+func (route *Route) info() RouteInfo {
+	middlewares := make([]string, len(route.middleWares))
+	for idx, middleware := range route.middleWares {
+		middlewares[idx] = funcName(middleware)
+	}
+
+	return RouteInfo{
+		Method:      route.method,
+		Path:        route.path,
+		Host:        route.host,
+		Name:        route.name,
+		Handler:     funcName(route.handler),
+		MiddleWares: middlewares,
+		File:        route.file,
+		Line:        route.line,
+		Disabled:    route.disabled,
+	}
+}
+
+// RouteTable returns the metadata of all the registered routes, in registration order.
+func (s *Server) RouteTable() []RouteInfo {
+	s.routesMu.RLock()
+	defer s.routesMu.RUnlock()
+
+	infos := make([]RouteInfo, len(s.routes))
+	for idx, route := range s.routes {
+		infos[idx] = route.info()
+	}
+
+	return infos
+}
+
+// PrintRoutes writes the registered routes to w as a table, e.g for start up logs:
+//
+//	server.PrintRoutes(os.Stdout)
+func (s *Server) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHANDLER\tMIDDLEWARES\tSOURCE")
+
+	for _, info := range s.RouteTable() {
+		middlewares := strings.Join(info.MiddleWares, ", ")
+		if middlewares == "" {
+			middlewares = "-"
+		}
+
+		name := info.Name
+		if name == "" {
+			name = "-"
+		}
+
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v:%v\n", info.Method, info.Host+info.Path, name, info.Handler, middlewares, info.File, info.Line)
+	}
+
+	return tw.Flush()
+}
+
+// DebugRoutes registers a GET route on path listing the registered routes as JSON.
+// It exposes the internals of the application, protect it with middlewares or only register it in development.
+func (s *Server) DebugRoutes(path string, middlewares ...HandlerFunc) (*Route, error) {
+	handler := func(req *Request, res IResponse) IResponse {
+		return res.SetStatus(status.HTTP_200_OK).Send(JSON{"routes": s.RouteTable()})
+	}
+
+	return s.AddRoute(path, get, handler, middlewares)
+}
+
+// Utility function to get the name of a function e.g main.allTasks, without the full package path.
+func funcName(fn any) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return ""
+	}
+
+	runtimeFunc := runtime.FuncForPC(value.Pointer())
+	if runtimeFunc == nil {
+		return ""
+	}
+
+	return path.Base(runtimeFunc.Name())
+}
+
+// Utility function to find where a route is registered: the first caller outside of goserve.
+func registrationSource() (string, int) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	packagePrefix := reflect.TypeOf(Route{}).PkgPath() + "."
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) {
+			return frame.File, frame.Line
+		}
+
+		if !more {
+			return "", 0
+		}
+	}
+}
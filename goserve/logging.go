@@ -0,0 +1,186 @@
+package goserve
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// Logger returns the logger of the server, set via Config.Logger, it defaults to slog.Default().
+// Handlers can use it to log with the same handler and level as the server.
+func (s *Server) Logger() *slog.Logger {
+	if s == nil || s.logger == nil {
+		return slog.Default()
+	}
+
+	return s.logger
+}
+
+// AccessLogFormat sets how AccessLogMiddleware writes requests.
+type AccessLogFormat int
+
+const (
+	// AccessLogCommon writes the Common Log Format, followed by the latency and the request ID e.g
+	// 127.0.0.1 - - [10/Oct/2024:13:55:36 +0000] "GET /tasks HTTP/1.1" 200 512 1.2ms 4bf92f3577b34da6
+	AccessLogCommon AccessLogFormat = iota
+
+	// AccessLogCombined adds the Referer and User-Agent headers to AccessLogCommon, before the latency and the request ID.
+	AccessLogCombined
+
+	// AccessLogJSON writes a JSON object per request, with the fields of AccessLogCombined.
+	AccessLogJSON
+)
+
+// Date format of the Common and Combined log formats.
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogConfig configures AccessLogMiddleware.
+type AccessLogConfig struct {
+	// Format of the lines, defaults to AccessLogCommon.
+	Format AccessLogFormat
+
+	// Output receives a line per request, defaults to os.Stdout.
+	Output io.Writer
+
+	// RequestIDHeader is the header holding the ID of the request, defaults to X-Request-ID.
+	// An ID is generated when the request doesn't have one, it's set on the request and response headers.
+	RequestIDHeader string
+}
+
+// accessLogEntry holds the details of a request written by AccessLogMiddleware.
+type accessLogEntry struct {
+	Time      string  `json:"time"`
+	RequestID string  `json:"request_id"`
+	ClientIP  string  `json:"client_ip"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Protocol  string  `json:"protocol"`
+	Status    int     `json:"status"`
+	Bytes     int     `json:"bytes"`
+	LatencyMs float64 `json:"latency_ms"`
+	Referer   string  `json:"referer"`
+	UserAgent string  `json:"user_agent"`
+
+	time    time.Time
+	latency time.Duration
+}
+
+// AccessLogMiddleware writes a line for every request once it's handled, mount it first so the latency covers the other middlewares e.g
+//
+//	server.AddMiddleWares(goserve.AccessLogMiddleware(goserve.AccessLogConfig{Format: goserve.AccessLogCombined}))
+//
+// The client IP is resolved through trusted proxies, see req.RealIP()
+func AccessLogMiddleware(config AccessLogConfig) HandlerFunc {
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+	if config.RequestIDHeader == "" {
+		config.RequestIDHeader = "X-Request-ID"
+	}
+
+	// Lines are written whole and one at a time, as requests are handled concurrently.
+	var outputMu sync.Mutex
+
+	return func(req *Request, res IResponse) IResponse {
+		start := time.Now()
+
+		requestID, exists := req.header(config.RequestIDHeader)
+		if !exists || requestID == "" {
+			requestID = newRequestID()
+			req.headers.Set(config.RequestIDHeader, requestID)
+		}
+		res.SetHeader(config.RequestIDHeader, requestID)
+
+		res = req.Next(res)
+
+		entry := accessLogEntry{
+			RequestID: requestID,
+			ClientIP:  req.RealIP(),
+			Method:    req.method,
+			Path:      req.path,
+			Protocol:  req.httpVersion,
+			Status:    cmp.Or(res.StatusCode(), status.HTTP_200_OK),
+			Bytes:     responseBodySize(req, res),
+			time:      start,
+			latency:   time.Since(start),
+		}
+		entry.Time = start.Format(time.RFC3339Nano)
+		entry.LatencyMs = float64(entry.latency.Microseconds()) / 1000
+		entry.Referer, _ = req.header("Referer")
+		entry.UserAgent, _ = req.header("User-Agent")
+
+		line := entry.format(config.Format)
+
+		outputMu.Lock()
+		defer outputMu.Unlock()
+
+		if _, err := io.WriteString(config.Output, line); err != nil {
+			req.server.Logger().Error("failed to write access log", "error", err)
+		}
+
+		return res
+	}
+}
+
+// format renders the entry as a line of the format, ending with a new line.
+func (entry accessLogEntry) format(format AccessLogFormat) string {
+	if format == AccessLogJSON {
+		encoded, _ := json.Marshal(entry)
+		return string(encoded) + "\n"
+	}
+
+	bytes := "-"
+	if entry.Bytes > 0 {
+		bytes = fmt.Sprint(entry.Bytes)
+	}
+
+	line := fmt.Sprintf(
+		"%v - - [%v] \"%v %v %v\" %v %v",
+		logValue(entry.ClientIP), entry.time.Format(accessLogTimeFormat), entry.Method, entry.Path, entry.Protocol, entry.Status, bytes,
+	)
+
+	if format == AccessLogCombined {
+		line += fmt.Sprintf(" %q %q", logValue(entry.Referer), logValue(entry.UserAgent))
+	}
+
+	return fmt.Sprintf("%v %v %v\n", line, entry.latency, entry.RequestID)
+}
+
+// logValue replaces empty values with "-" as done by the Common and Combined log formats.
+func logValue(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
+
+// responseBodySize returns the size of the body sent for the response, HEAD responses don't have one.
+func responseBodySize(req *Request, res IResponse) int {
+	if req.method == head {
+		return 0
+	}
+
+	if response, ok := res.(interface{ BodyAsString() string }); ok {
+		return len(response.BodyAsString())
+	}
+
+	return 0
+}
+
+// newRequestID returns a random 16 characters hex ID.
+func newRequestID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,284 @@
+package goserve
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// Routes registered for the same method and path can be told apart by matchers on the request:
+//
+//	jsonReport, _ := server.GET("/report", reportJSON)
+//	jsonReport.Produces("application/json")
+//
+//	csvReport, _ := server.GET("/report", reportCSV)
+//	csvReport.Produces("text/csv")
+//
+// Among the routes whose header and query matchers match, the one best matching the Accept header is picked.
+// Ties go to the route with more matchers, then to the first registered.
+// A route without matchers is the fallback, picked when no other route fits. It has to be registered last,
+// as a route registered for the same method and path as a route without matchers is a duplicate, see ErrRouteConflict.
+// The request gets a 404 response if no route matches its headers and query,
+// 415 if none consumes its Content-Type and 406 if none produces a type it accepts.
+
+// A header or query parameter a route requires, any value is accepted if value is empty.
+type valueMatcher struct {
+	name  string
+	value string
+}
+
+// Quality given to routes that don't declare the types they produce, lower than any quality a client can set.
+// They are acceptable whatever the Accept header, but routes producing an accepted type are preferred.
+const undeclaredQuality = 0.0001
+
+// Header makes the route only match requests with the header set to value, or set to any value if value is empty.
+func (route *Route) Header(name string, value string) *Route {
+	defer route.lock()()
+
+	route.headerMatchers = append(route.headerMatchers, valueMatcher{name, value})
+	return route
+}
+
+// Query makes the route only match requests with the query parameter set to value, or set to any value if value is empty.
+func (route *Route) Query(name string, value string) *Route {
+	defer route.lock()()
+
+	route.queryMatchers = append(route.queryMatchers, valueMatcher{name, value})
+	return route
+}
+
+// Consumes makes the route only match requests whose Content-Type is one of the media types,
+// which can hold wildcards e.g text/*
+// Requests without a body are matched whatever their Content-Type.
+func (route *Route) Consumes(mediaTypes ...string) *Route {
+	defer route.lock()()
+
+	for _, mediaType := range mediaTypes {
+		route.consumes = append(route.consumes, normalizeMediaType(mediaType))
+	}
+	return route
+}
+
+// Produces declares the media types the route responds with, they are negotiated with the Accept header.
+// The negotiated type is available to the handler via req.MediaType(), and set as the Content-Type of the response,
+// handlers can still set another one.
+func (route *Route) Produces(mediaTypes ...string) *Route {
+	defer route.lock()()
+
+	for _, mediaType := range mediaTypes {
+		route.produces = append(route.produces, normalizeMediaType(mediaType))
+	}
+	return route
+}
+
+// MediaType returns the media type negotiated with the Accept header, among those produced by the matched route.
+// It's empty if the route doesn't declare the types it produces.
+func (req *Request) MediaType() string {
+	return req.mediaType
+}
+
+// hasMatchers checks if the route declares any matcher.
+func (route *Route) hasMatchers() bool {
+	return route.matcherCount() > 0
+}
+
+func (route *Route) matcherCount() int {
+	count := len(route.headerMatchers) + len(route.queryMatchers)
+
+	if len(route.consumes) > 0 {
+		count++
+	}
+	if len(route.produces) > 0 {
+		count++
+	}
+
+	return count
+}
+
+// matcherSignature describes the matchers of the route, routes with the same signature match the same requests.
+func (route *Route) matcherSignature() string {
+	parts := []string{}
+
+	for _, matcher := range route.headerMatchers {
+		parts = append(parts, "header:"+strings.ToLower(matcher.name)+"="+matcher.value)
+	}
+	for _, matcher := range route.queryMatchers {
+		parts = append(parts, "query:"+matcher.name+"="+matcher.value)
+	}
+	for _, mediaType := range route.consumes {
+		parts = append(parts, "consumes:"+mediaType)
+	}
+	for _, mediaType := range route.produces {
+		parts = append(parts, "produces:"+mediaType)
+	}
+	slices.Sort(parts)
+
+	return strings.Join(parts, ";")
+}
+
+// matchesRequest checks the header and query matchers of the route.
+func (route *Route) matchesRequest(req *Request) bool {
+	for _, matcher := range route.headerMatchers {
+		value, exists := req.header(matcher.name)
+		if !exists || (matcher.value != "" && value != matcher.value) {
+			return false
+		}
+	}
+
+	for _, matcher := range route.queryMatchers {
+		value, exists := req.QueryParams().Get(matcher.name)
+		if !exists || (matcher.value != "" && value != matcher.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// consumesRequest checks the Content-Type of the request against the types consumed by the route.
+func (route *Route) consumesRequest(req *Request) bool {
+	if len(route.consumes) == 0 || len(req.body) == 0 {
+		return true
+	}
+
+	contentType, exists := req.header("Content-Type")
+	if !exists {
+		return false
+	}
+
+	contentType = normalizeMediaType(contentType)
+	for _, mediaType := range route.consumes {
+		if mediaTypeMatches(mediaType, contentType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiate returns the produced type best matching the accepted media ranges and its quality.
+// The quality is 0 if none is acceptable.
+func (route *Route) negotiate(accepted []qualityValue) (string, float64) {
+	if len(route.produces) == 0 {
+		return "", undeclaredQuality
+	}
+
+	if len(accepted) == 0 {
+		return route.produces[0], 1
+	}
+
+	bestType, bestQuality := "", 0.0
+	for _, mediaType := range route.produces {
+		if quality := acceptedQuality(accepted, mediaType); quality > bestQuality {
+			bestType, bestQuality = mediaType, quality
+		}
+	}
+
+	return bestType, bestQuality
+}
+
+// selectRoute picks the route handling the request among the routes registered for its method and path.
+// When none fits, it returns the status to respond with: 404, 415 or 406.
+func selectRoute(req *Request, routes []*Route) (*Route, int) {
+	if len(routes) == 1 && !routes[0].hasMatchers() {
+		return routes[0], 0
+	}
+
+	matched := []*Route{}
+	for _, route := range routes {
+		if route.matchesRequest(req) {
+			matched = append(matched, route)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, status.HTTP_404_NOT_FOUND
+	}
+
+	consumed := []*Route{}
+	for _, route := range matched {
+		if route.consumesRequest(req) {
+			consumed = append(consumed, route)
+		}
+	}
+	if len(consumed) == 0 {
+		return nil, status.HTTP_415_UNSUPPORTED_MEDIA_TYPE
+	}
+
+	var accepted []qualityValue
+	if accept, exists := req.header("Accept"); exists {
+		accepted = parseQualityList(accept)
+	}
+
+	var bestRoute *Route
+	bestType, bestQuality := "", 0.0
+
+	for _, route := range consumed {
+		mediaType, quality := route.negotiate(accepted)
+		if quality <= 0 {
+			continue
+		}
+
+		if bestRoute == nil || quality > bestQuality || (quality == bestQuality && route.matcherCount() > bestRoute.matcherCount()) {
+			bestRoute, bestType, bestQuality = route, mediaType, quality
+		}
+	}
+
+	if bestRoute == nil {
+		return nil, status.HTTP_406_NOT_ACCEPTABLE
+	}
+
+	req.mediaType = bestType
+	return bestRoute, 0
+}
+
+// acceptedQuality returns the quality of the most specific media range accepting the media type, 0 if none does.
+func acceptedQuality(accepted []qualityValue, mediaType string) float64 {
+	quality, specificity := 0.0, -1
+
+	for _, mediaRange := range accepted {
+		mediaRangeType := normalizeMediaType(mediaRange.value)
+		if !mediaTypeMatches(mediaRangeType, mediaType) {
+			continue
+		}
+
+		if rangeSpecificity := mediaRangeSpecificity(mediaRangeType); rangeSpecificity > specificity {
+			quality, specificity = mediaRange.quality, rangeSpecificity
+		}
+	}
+
+	return quality
+}
+
+// mediaTypeMatches checks if the media type matches the media range, which can be */* or type/*
+func mediaTypeMatches(mediaRange string, mediaType string) bool {
+	rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+	typeName, subtype, _ := strings.Cut(mediaType, "/")
+
+	if rangeType == "*" {
+		return true
+	}
+	if rangeType != typeName {
+		return false
+	}
+
+	return rangeSubtype == "*" || rangeSubtype == subtype
+}
+
+// mediaRangeSpecificity ranks media ranges: */* is 0, type/* is 1 and type/subtype is 2.
+func mediaRangeSpecificity(mediaRange string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Utility function to drop the parameters and case of a media type e.g Application/JSON; charset=utf-8 gives application/json
+func normalizeMediaType(mediaType string) string {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
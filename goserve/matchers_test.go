@@ -0,0 +1,135 @@
+package goserve
+
+import (
+	"testing"
+)
+
+func sendRequest(t *testing.T, server *Server, raw string) IResponse {
+	t.Helper()
+
+	req, err := NewRequest(raw, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server.HandleRequest(req)
+}
+
+func TestUnmetMatchersRespondNotFound(t *testing.T) {
+	server := NewServer(Config{})
+	route, _ := server.GET("/report", func(req *Request, res IResponse) IResponse { return res.Send("v2") })
+	route.Header("X-Version", "2")
+
+	res := sendRequest(t, server, "GET /report HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	if res.StatusCode() != 404 {
+		t.Errorf("got status %v, want 404", res.StatusCode())
+	}
+	if allow, exists := res.Headers().Get("Allow"); exists {
+		t.Errorf("got Allow header %q on a 404 response", allow)
+	}
+
+	res = sendRequest(t, server, "GET /report HTTP/1.1\r\nHost: localhost\r\nX-Version: 2\r\n\r\n")
+	if res.StatusCode() != 0 && res.StatusCode() != 200 {
+		t.Errorf("got status %v, want 200", res.StatusCode())
+	}
+}
+
+func TestHostMatcherMissFallsThrough(t *testing.T) {
+	server := NewServer(Config{})
+	server.GET("/report", func(req *Request, res IResponse) IResponse { return res.Send("default") })
+
+	route, _ := server.Host("api.example.com").GET("/report", func(req *Request, res IResponse) IResponse { return res.Send("host") })
+	route.Header("X-Version", "2")
+
+	res := sendRequest(t, server, "GET /report HTTP/1.1\r\nHost: api.example.com\r\n\r\n")
+	if res.Body() != "default" {
+		t.Errorf("got body %v, want the route of any host", res.Body())
+	}
+
+	res = sendRequest(t, server, "GET /report HTTP/1.1\r\nHost: api.example.com\r\nX-Version: 2\r\n\r\n")
+	if res.Body() != "host" {
+		t.Errorf("got body %v, want the host route", res.Body())
+	}
+}
+
+func TestConsumesNonJSONBody(t *testing.T) {
+	server := NewServer(Config{})
+
+	csvRoute, _ := server.POST("/import", func(req *Request, res IResponse) IResponse { return res.Send(string(req.RawBody())) })
+	csvRoute.Consumes("text/csv")
+	server.POST("/import", func(req *Request, res IResponse) IResponse { return res.Send("json") })
+
+	res := sendRequest(t, server, "POST /import HTTP/1.1\r\nHost: localhost\r\nContent-Type: text/csv\r\n\r\na,b\nc,d\n")
+	if res.Body() != "a,b\nc,d\n" {
+		t.Errorf("got body %q, want the CSV body as sent", res.Body())
+	}
+
+	res = sendRequest(t, server, "POST /import HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n\r\n{\"a\": 1}")
+	if res.Body() != "json" {
+		t.Errorf("got body %v, want the JSON route", res.Body())
+	}
+
+	if _, err := NewRequest("POST /import HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n\r\na,b", nil, nil); err == nil {
+		t.Error("got no error for a malformed JSON body")
+	}
+}
+
+func TestMountKeepsRouteSettings(t *testing.T) {
+	server := NewServer(Config{})
+	server.GET("/api/report", func(req *Request, res IResponse) IResponse { return res.Send("v1") })
+
+	router := NewRouter()
+	route, _ := router.GET("/report", func(req *Request, res IResponse) IResponse { return res.Send("v2") })
+	route.Header("X-Version", "2").Name("report-v2").Summary("Report, version 2")
+
+	if err := server.Mount("/api", router); err != nil {
+		t.Fatal(err)
+	}
+
+	res := sendRequest(t, server, "GET /api/report HTTP/1.1\r\nHost: localhost\r\nX-Version: 2\r\n\r\n")
+	if res.Body() != "v2" {
+		t.Errorf("got body %v, want the mounted route", res.Body())
+	}
+
+	res = sendRequest(t, server, "GET /api/report HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	if res.Body() != "v1" {
+		t.Errorf("got body %v, want the route without matchers", res.Body())
+	}
+
+	if info := route.Info(); info.Path != "/api/report" || info.Name != "report-v2" {
+		t.Errorf("got route info %+v, want the mounted route", info)
+	}
+	if url, err := server.URL("report-v2", nil); err != nil || url != "/api/report" {
+		t.Errorf("got URL %q and error %v, want /api/report", url, err)
+	}
+}
+
+func TestProducesSetsContentType(t *testing.T) {
+	server := NewServer(Config{})
+
+	csvRoute, _ := server.GET("/report", func(req *Request, res IResponse) IResponse { return res.Send("a,b\n") })
+	csvRoute.Produces("text/csv")
+	explicitRoute, _ := server.GET("/export", func(req *Request, res IResponse) IResponse {
+		return res.SetHeader("Content-Type", "text/csv; charset=utf-8").Send("a,b\n")
+	})
+	explicitRoute.Produces("text/csv")
+	server.GET("/tasks", func(req *Request, res IResponse) IResponse { return res.Send(JSON{"tasks": []string{}}) })
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/report", "text/csv"},
+		{"/export", "text/csv; charset=utf-8"},
+		{"/tasks", "application/json"},
+	}
+
+	for _, test := range tests {
+		res := sendRequest(t, server, "GET "+test.path+" HTTP/1.1\r\nHost: localhost\r\nAccept: text/csv, application/json\r\n\r\n")
+		res.GetResponseByte(false)
+
+		if contentType, _ := res.Headers().Get("Content-Type"); contentType != test.contentType {
+			t.Errorf("got Content-Type %q for %v, want %q", contentType, test.path, test.contentType)
+		}
+	}
+}
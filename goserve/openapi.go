@@ -0,0 +1,614 @@
+package goserve
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// Routes can describe themselves for the OpenAPI 3.1 document built by server.OpenAPI():
+//
+//	route, _ := server.POST("/tasks", createTask)
+//	route.Summary("Create a task").Tags("tasks").Request(Task{}).Response(status.HTTP_201_CREATED, Task{}).Security("bearer")
+//
+// Schemas are built by reflecting over the Go types: fields are named by their json tag, `valid` tags add constraints
+// (required, email, url, uuid, range, length, stringlength, in, matches) and fields tagged with
+// path, query, header or cookie become parameters instead of body properties, as for req.Bind().
+
+const openAPIVersion = "3.1.0"
+
+// OpenAPIInfo holds the document level details of the OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string
+	Version     string
+	Description string
+
+	// Security schemes referenced by route.Security(), keyed by name.
+	SecuritySchemes map[string]SecurityScheme
+}
+
+// SecurityScheme is an OpenAPI security scheme e.g SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+
+	// Name and location (query, header or cookie) of the key for apiKey schemes.
+	Name string `json:"name,omitempty"`
+	In   string `json:"in,omitempty"`
+}
+
+// A response documented with route.Response()
+type routeResponse struct {
+	statusCode int
+	bodyType   reflect.Type
+}
+
+// Summary sets the summary of the route in the OpenAPI document.
+func (route *Route) Summary(summary string) *Route {
+	defer route.lock()()
+
+	route.summary = summary
+	return route
+}
+
+// Description sets the description of the route in the OpenAPI document.
+func (route *Route) Description(description string) *Route {
+	defer route.lock()()
+
+	route.description = description
+	return route
+}
+
+// Tags groups the route under tags in the OpenAPI document.
+func (route *Route) Tags(tags ...string) *Route {
+	defer route.lock()()
+
+	route.tags = append(route.tags, tags...)
+	return route
+}
+
+// Request documents the type the route binds the request to with req.Bind(), v is a value of the type e.g Task{}
+// Its fields tagged with path, query, header or cookie are documented as parameters, the others as the JSON body.
+func (route *Route) Request(v any) *Route {
+	defer route.lock()()
+
+	route.requestType = reflect.TypeOf(v)
+	return route
+}
+
+// Response documents a response of the route, v is a value of the body type e.g Task{}, or nil for responses without body.
+func (route *Route) Response(statusCode int, v any) *Route {
+	defer route.lock()()
+
+	route.responses = append(route.responses, routeResponse{statusCode, reflect.TypeOf(v)})
+	return route
+}
+
+// Security documents the security schemes required by the route, by their name in OpenAPIInfo.SecuritySchemes.
+func (route *Route) Security(schemes ...string) *Route {
+	defer route.lock()()
+
+	route.security = append(route.security, schemes...)
+	return route
+}
+
+// OpenAPI builds the OpenAPI 3.1 JSON document describing the registered routes.
+// The output is stable for the same routes, so it can be exported and diffed in CI.
+func (s *Server) OpenAPI(info OpenAPIInfo) ([]byte, error) {
+	return json.MarshalIndent(s.openAPIDocument(info), "", "  ")
+}
+
+// ServeOpenAPI registers a GET route on path serving the OpenAPI document, built on each request.
+func (s *Server) ServeOpenAPI(path string, info OpenAPIInfo, middlewares ...HandlerFunc) (*Route, error) {
+	handler := func(req *Request, res IResponse) IResponse {
+		document, err := s.OpenAPI(info)
+		if err != nil {
+			return res.SetStatus(status.HTTP_500_INTERNAL_SERVER_ERROR).Send(JSON{"error": err.Error()})
+		}
+
+		return res.SetStatus(status.HTTP_200_OK).Send(document)
+	}
+
+	return s.AddRoute(path, get, handler, middlewares)
+}
+
+func (s *Server) openAPIDocument(info OpenAPIInfo) JSON {
+	s.routesMu.RLock()
+	defer s.routesMu.RUnlock()
+
+	schemas := JSON{}
+	paths := JSON{}
+
+	for _, route := range s.routes {
+		if route.disabled {
+			continue
+		}
+
+		path := openAPIPath(route.tokens)
+		pathItem, exists := paths[path].(JSON)
+		if !exists {
+			pathItem = JSON{}
+			paths[path] = pathItem
+		}
+
+		method := strings.ToLower(route.method)
+		operation := route.openAPIOperation(schemas)
+
+		// Routes told apart by matchers share an operation, their media types are merged.
+		if existing, exists := pathItem[method].(JSON); exists {
+			mergeOperations(existing, operation)
+			continue
+		}
+		pathItem[method] = operation
+	}
+
+	infoObject := JSON{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		infoObject["description"] = info.Description
+	}
+
+	document := JSON{
+		"openapi": openAPIVersion,
+		"info":    infoObject,
+		"paths":   paths,
+	}
+
+	components := JSON{}
+	if len(schemas) > 0 {
+		components["schemas"] = schemas
+	}
+	if len(info.SecuritySchemes) > 0 {
+		components["securitySchemes"] = info.SecuritySchemes
+	}
+	if len(components) > 0 {
+		document["components"] = components
+	}
+
+	return document
+}
+
+// openAPIOperation describes the route as an OpenAPI operation, adding the schemas of named types to schemas.
+func (route *Route) openAPIOperation(schemas JSON) JSON {
+	operation := JSON{}
+
+	if route.name != "" {
+		operation["operationId"] = route.name
+	}
+	if route.summary != "" {
+		operation["summary"] = route.summary
+	}
+	if route.description != "" {
+		operation["description"] = route.description
+	}
+	if len(route.tags) > 0 {
+		operation["tags"] = route.tags
+	}
+
+	if parameters := route.openAPIParameters(schemas); len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if route.requestType != nil && hasBodyFields(route.requestType) {
+		operation["requestBody"] = JSON{
+			"required": true,
+			"content":  openAPIContent(route.consumes, typeSchema(route.requestType, schemas)),
+		}
+	}
+
+	responses := JSON{}
+	for _, response := range route.responses {
+		responseObject := JSON{"description": status.HTTPStatuses[response.statusCode].Message}
+		if response.bodyType != nil {
+			responseObject["content"] = openAPIContent(route.produces, typeSchema(response.bodyType, schemas))
+		}
+		responses[strconv.Itoa(response.statusCode)] = responseObject
+	}
+	if len(responses) == 0 {
+		responses[strconv.Itoa(status.HTTP_200_OK)] = JSON{"description": status.HTTPStatuses[status.HTTP_200_OK].Message}
+	}
+	operation["responses"] = responses
+
+	if len(route.security) > 0 {
+		requirements := []JSON{}
+		for _, scheme := range route.security {
+			requirements = append(requirements, JSON{scheme: []string{}})
+		}
+		operation["security"] = requirements
+	}
+
+	return operation
+}
+
+// openAPIParameters documents the path parameters of the route, then the fields of the request type read from
+// the query, headers or cookies.
+func (route *Route) openAPIParameters(schemas JSON) []JSON {
+	parameters := []JSON{}
+
+	fields := map[string]map[string]reflect.StructField{}
+	if route.requestType != nil {
+		fields = sourceFields(route.requestType)
+	}
+
+	for _, token := range route.tokens {
+		if token.kind == staticToken {
+			continue
+		}
+
+		name := token.text
+		if token.kind == wildcardToken {
+			name = "wildcard"
+		}
+
+		schema := JSON{"type": "string"}
+		if field, exists := fields[sourcePath][token.text]; exists {
+			schema = typeSchema(field.Type, schemas)
+		}
+		if token.constraint != "" {
+			schema = constraintSchema(token.constraint)
+		}
+
+		parameters = append(parameters, JSON{"name": name, "in": "path", "required": true, "schema": schema})
+	}
+
+	for _, source := range []string{sourceQuery, sourceHeader, sourceCookie} {
+		for _, field := range orderedFields(route.requestType, source) {
+			name := field.Tag.Get(source)
+			schema := typeSchema(field.Type, schemas)
+			required := applyValidTag(schema, field.Tag.Get("valid"))
+
+			if defaultValue, exists := field.Tag.Lookup("default"); exists {
+				schema["default"] = typedDefault(field, defaultValue)
+			}
+
+			parameters = append(parameters, JSON{"name": name, "in": source, "required": required, "schema": schema})
+		}
+	}
+
+	return parameters
+}
+
+// mergeOperations adds the media types of operation to those of existing, documenting routes told apart by matchers.
+func mergeOperations(existing JSON, operation JSON) {
+	if requestBody, exists := operation["requestBody"].(JSON); exists {
+		if existingBody, exists := existing["requestBody"].(JSON); exists {
+			mergeContent(existingBody, requestBody)
+		} else {
+			existing["requestBody"] = requestBody
+		}
+	}
+
+	existingResponses := existing["responses"].(JSON)
+	for statusCode, response := range operation["responses"].(JSON) {
+		if existingResponse, exists := existingResponses[statusCode].(JSON); exists {
+			mergeContent(existingResponse, response.(JSON))
+		} else {
+			existingResponses[statusCode] = response
+		}
+	}
+}
+
+func mergeContent(existing JSON, other JSON) {
+	otherContent, exists := other["content"].(JSON)
+	if !exists {
+		return
+	}
+
+	existingContent, exists := existing["content"].(JSON)
+	if !exists {
+		existing["content"] = otherContent
+		return
+	}
+
+	for mediaType, mediaTypeObject := range otherContent {
+		if _, exists := existingContent[mediaType]; !exists {
+			existingContent[mediaType] = mediaTypeObject
+		}
+	}
+}
+
+// openAPIContent maps the media types, application/json if there are none, to the schema.
+func openAPIContent(mediaTypes []string, schema JSON) JSON {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+
+	content := JSON{}
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = JSON{"schema": schema}
+	}
+
+	return content
+}
+
+// Utility function to write a route pattern as an OpenAPI path e.g /tasks/:id<int> gives /tasks/{id}
+func openAPIPath(tokens []patternToken) string {
+	var b strings.Builder
+
+	for _, token := range tokens {
+		switch token.kind {
+		case staticToken:
+			b.WriteString(unescapeSegment(token.text))
+		case wildcardToken:
+			b.WriteString("{wildcard}")
+		default:
+			b.WriteString("{" + token.text + "}")
+		}
+	}
+
+	return b.String()
+}
+
+// constraintSchema describes the values allowed by a path parameter constraint.
+func constraintSchema(constraint string) JSON {
+	switch constraint {
+	case "int":
+		return JSON{"type": "integer"}
+	case "uint":
+		return JSON{"type": "integer", "minimum": 0}
+	case "uuid":
+		return JSON{"type": "string", "format": "uuid"}
+	}
+
+	expression := constraint
+	if namedExpression, exists := paramTypes[constraint]; exists {
+		expression = namedExpression
+	}
+
+	return JSON{"type": "string", "pattern": "^(?:" + expression + ")$"}
+}
+
+// typeSchema returns the JSON schema of a Go type.
+// Named structs are added to schemas and referenced, so recursive types are supported.
+func typeSchema(t reflect.Type, schemas JSON) JSON {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return JSON{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return JSON{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return JSON{"type": "string"}
+
+	case reflect.Bool:
+		return JSON{"type": "boolean"}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return JSON{"type": "integer", "format": "int32"}
+
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return JSON{"type": "integer", "format": "int64"}
+
+	case reflect.Float32:
+		return JSON{"type": "number", "format": "float"}
+
+	case reflect.Float64:
+		return JSON{"type": "number", "format": "double"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return JSON{"type": "string", "contentEncoding": "base64"}
+		}
+		return JSON{"type": "array", "items": typeSchema(t.Elem(), schemas)}
+
+	case reflect.Map:
+		return JSON{"type": "object", "additionalProperties": typeSchema(t.Elem(), schemas)}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return objectSchema(t, schemas)
+		}
+
+		ref := JSON{"$ref": "#/components/schemas/" + t.Name()}
+		if _, exists := schemas[t.Name()]; !exists {
+			// Set before building the schema so fields referring back to the type find it.
+			schemas[t.Name()] = JSON{}
+			schemas[t.Name()] = objectSchema(t, schemas)
+		}
+
+		return ref
+	}
+
+	// Interfaces and other kinds accept any value.
+	return JSON{}
+}
+
+// objectSchema describes the body fields of a struct, embedded structs' fields included.
+func objectSchema(t reflect.Type, schemas JSON) JSON {
+	properties := JSON{}
+	required := []string{}
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+				addFields(field.Type)
+				continue
+			}
+
+			source, name := fieldSource(field)
+			if source != sourceBody {
+				continue
+			}
+
+			schema := typeSchema(field.Type, schemas)
+			if applyValidTag(schema, field.Tag.Get("valid")) {
+				required = append(required, name)
+			}
+			if defaultValue, exists := field.Tag.Lookup("default"); exists {
+				schema["default"] = typedDefault(field, defaultValue)
+			}
+
+			properties[name] = schema
+		}
+	}
+	addFields(t)
+
+	schema := JSON{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// Matches govalidator rules with arguments e.g range(1|10)
+var validRuleWithArgs = regexp.MustCompile(`^(\w+)\((.*)\)$`)
+
+// applyValidTag adds the constraints of a `valid` tag to the schema and reports if the field is required.
+// Constraints of references are left out, the referenced schema can't be changed.
+func applyValidTag(schema JSON, tag string) bool {
+	required := false
+	_, isRef := schema["$ref"]
+
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		args := []string{}
+
+		if match := validRuleWithArgs.FindStringSubmatch(rule); match != nil {
+			rule = match[1]
+			args = strings.Split(match[2], "|")
+		}
+
+		if rule == "required" {
+			required = true
+			continue
+		}
+		if isRef {
+			continue
+		}
+
+		switch {
+		case rule == "email":
+			schema["format"] = "email"
+		case rule == "url" || rule == "requrl":
+			schema["format"] = "uri"
+		case strings.HasPrefix(rule, "uuid"):
+			schema["format"] = "uuid"
+		case rule == "matches" && len(args) > 0:
+			schema["pattern"] = args[0]
+		case rule == "in":
+			schema["enum"] = args
+		case rule == "range" && len(args) == 2:
+			setNumber(schema, "minimum", args[0])
+			setNumber(schema, "maximum", args[1])
+		case (rule == "length" || rule == "stringlength" || rule == "runelength") && len(args) == 2:
+			setNumber(schema, "minLength", args[0])
+			setNumber(schema, "maxLength", args[1])
+		}
+	}
+
+	return required
+}
+
+// typedDefault converts the `default` tag of a field to the field type as req.Bind() does, so it's documented
+// with the right JSON type. The raw value is used if it can't be converted.
+func typedDefault(field reflect.StructField, raw string) any {
+	value := reflect.New(field.Type).Elem()
+	if err := setFieldValue(value, raw, field.Tag.Get("format")); err != nil {
+		return raw
+	}
+
+	return value.Interface()
+}
+
+func setNumber(schema JSON, key string, value string) {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		schema[key] = number
+	}
+}
+
+// hasBodyFields checks if a struct type has fields read from the JSON body.
+func hasBodyFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if hasBodyFields(field.Type) {
+				return true
+			}
+			continue
+		}
+
+		if source, _ := fieldSource(field); source == sourceBody {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sourceFields indexes the fields of a struct type read from requests sources other than the body, by source then name.
+func sourceFields(t reflect.Type) map[string]map[string]reflect.StructField {
+	fields := map[string]map[string]reflect.StructField{}
+
+	for _, source := range bindingSources {
+		fields[source] = map[string]reflect.StructField{}
+
+		for _, field := range orderedFields(t, source) {
+			fields[source][field.Tag.Get(source)] = field
+		}
+	}
+
+	return fields
+}
+
+// orderedFields returns the fields of a struct type read from the source, in declaration order.
+func orderedFields(t reflect.Type, source string) []reflect.StructField {
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, orderedFields(field.Type, source)...)
+			continue
+		}
+
+		if fieldSource, _ := fieldSource(field); fieldSource == source {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
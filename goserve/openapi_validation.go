@@ -0,0 +1,851 @@
+package goserve
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// OpenAPIValidationOptions configures the OpenAPIValidationMiddleware.
+type OpenAPIValidationOptions struct {
+
+	// ValidateResponses checks that responses match the status codes and schemas of the document.
+	// Responses that don't are replaced by a 500 response listing the mismatches, it's meant for development.
+	ValidateResponses bool
+}
+
+// A source of the response, used for the FieldErrors of responses checked with OpenAPIValidationOptions.ValidateResponses
+const sourceResponse = "response"
+
+// openAPISpec is an OpenAPI document prepared for validating requests.
+type openAPISpec struct {
+	root       map[string]any
+	operations []*specOperation
+
+	// Compiled pattern keywords of the schemas, by expression.
+	// It's filled when the document is parsed and only read while requests are validated, as they are validated concurrently.
+	patterns map[string]*regexp.Regexp
+}
+
+// specOperation is an operation of the document, with the parameters of its path item.
+type specOperation struct {
+	method string
+
+	// Matches normalized request paths, capturing the path parameters in the order of paramNames.
+	template   *regexp.Regexp
+	paramNames []string
+
+	parameters  []specParameter
+	requestBody map[string]any
+	responses   map[string]any
+}
+
+type specParameter struct {
+	name     string
+	in       string
+	required bool
+	schema   any
+}
+
+// OpenAPIValidationMiddleware validates requests against the operations of an OpenAPI 3 JSON document, e.g
+//
+//	validation, err := goserve.OpenAPIValidationMiddleware(document, goserve.OpenAPIValidationOptions{})
+//	server.AddMiddleWares(validation)
+//
+// Path, query, header and cookie parameters are checked against their schema, invalid ones get a 400 response.
+// JSON bodies are checked against the schema of their media type, invalid ones get a 422 response (see SendValidationErrors),
+// and bodies of a media type the operation doesn't accept get a 415 response.
+// Requests that don't match an operation of the document are passed on unchecked.
+// An error is returned if the document can't be parsed.
+func OpenAPIValidationMiddleware(document []byte, options OpenAPIValidationOptions) (HandlerFunc, error) {
+	spec, err := parseOpenAPISpec(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(req *Request, res IResponse) IResponse {
+		operation, pathValues := spec.match(req)
+		if operation == nil {
+			return req.Next(res)
+		}
+
+		if errs := spec.validateParameters(req, operation, pathValues); len(errs) > 0 {
+			return res.SetStatus(status.HTTP_400_BAD_REQUEST).Send(
+				JSON{
+					"error":  "invalid request",
+					"fields": errs,
+				},
+			)
+		}
+
+		if statusCode, errs := spec.validateBody(req, operation); len(errs) > 0 || statusCode != 0 {
+			switch statusCode {
+			case status.HTTP_422_UNPROCESSABLE_ENTITY:
+				return SendValidationErrors(res, errs)
+			case status.HTTP_415_UNSUPPORTED_MEDIA_TYPE:
+				return res.SetStatus(statusCode).Send("Unsupported media type.")
+			default:
+				return res.SetStatus(statusCode).Send(JSON{"error": "invalid request", "fields": errs})
+			}
+		}
+
+		res = req.Next(res)
+
+		if options.ValidateResponses {
+			if errs := spec.validateResponse(req, operation, res); len(errs) > 0 {
+				return res.SetStatus(status.HTTP_500_INTERNAL_SERVER_ERROR).Send(
+					JSON{
+						"error":  "response does not match the OpenAPI document",
+						"fields": errs,
+					},
+				)
+			}
+		}
+
+		return res
+	}, nil
+}
+
+// Utility function to parse an OpenAPI document and compile the path templates of its operations.
+func parseOpenAPISpec(document []byte) (*openAPISpec, error) {
+	spec := &openAPISpec{patterns: map[string]*regexp.Regexp{}}
+
+	if err := json.Unmarshal(document, &spec.root); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %v", err.Error())
+	}
+
+	paths, ok := spec.root["paths"].(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid OpenAPI document: paths is missing")
+	}
+
+	for path, item := range paths {
+		pathItem, ok := spec.resolve(item).(map[string]any)
+		if !ok {
+			continue
+		}
+
+		template, paramNames, err := compilePathTemplate(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, method := range httpMethods {
+			operationObject, ok := pathItem[strings.ToLower(method)].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			operation := &specOperation{
+				method:     method,
+				template:   template,
+				paramNames: paramNames,
+				parameters: spec.parameters(pathItem["parameters"], operationObject["parameters"]),
+			}
+			operation.requestBody, _ = spec.resolve(operationObject["requestBody"]).(map[string]any)
+			operation.responses, _ = operationObject["responses"].(map[string]any)
+
+			spec.operations = append(spec.operations, operation)
+		}
+	}
+
+	// Templates with fewer parameters are more specific e.g /tasks/new is tried before /tasks/{id}
+	slices.SortStableFunc(spec.operations, func(a *specOperation, b *specOperation) int {
+		if len(a.paramNames) != len(b.paramNames) {
+			return len(a.paramNames) - len(b.paramNames)
+		}
+		return strings.Compare(a.template.String(), b.template.String())
+	})
+
+	spec.compilePatterns(spec.root)
+
+	return spec, nil
+}
+
+// compilePatterns walks the document and compiles the pattern keywords it holds.
+// Invalid expressions are kept as nil and ignored when validating, like unknown formats.
+func (spec *openAPISpec) compilePatterns(node any) {
+	switch typedNode := node.(type) {
+	case map[string]any:
+		for key, value := range typedNode {
+			if pattern, ok := value.(string); ok && key == "pattern" {
+				if _, compiled := spec.patterns[pattern]; !compiled {
+					spec.patterns[pattern], _ = regexp.Compile(pattern)
+				}
+				continue
+			}
+
+			spec.compilePatterns(value)
+		}
+
+	case []any:
+		for _, item := range typedNode {
+			spec.compilePatterns(item)
+		}
+	}
+}
+
+// Utility function to compile an OpenAPI path template e.g /tasks/{id} into a regular expression matching normalized paths.
+func compilePathTemplate(path string) (*regexp.Regexp, []string, error) {
+	var expression strings.Builder
+	paramNames := []string{}
+
+	expression.WriteString("^")
+	for rest := path; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			expression.WriteString(regexp.QuoteMeta(escapePathTemplate(rest)))
+			break
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, nil, fmt.Errorf("invalid OpenAPI document: parameter of path %q isn't closed with }", path)
+		}
+
+		expression.WriteString(regexp.QuoteMeta(escapePathTemplate(rest[:start])))
+		expression.WriteString("([^/]+)")
+		paramNames = append(paramNames, rest[start+1:start+end])
+		rest = rest[start+end+1:]
+	}
+	expression.WriteString("$")
+
+	template, err := regexp.Compile(expression.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid OpenAPI document: path %q: %v", path, err.Error())
+	}
+
+	return template, paramNames, nil
+}
+
+// escapePathTemplate writes the static text of a path template in its normalized form.
+func escapePathTemplate(static string) string {
+	segments := strings.Split(static, "/")
+	for idx, segment := range segments {
+		segments[idx] = escapeSegment(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// parameters merges the parameters of a path item with those of an operation, which override them.
+func (spec *openAPISpec) parameters(pathParameters any, operationParameters any) []specParameter {
+	parameters := []specParameter{}
+
+	for _, list := range []any{pathParameters, operationParameters} {
+		items, _ := list.([]any)
+
+		for _, item := range items {
+			parameterObject, ok := spec.resolve(item).(map[string]any)
+			if !ok {
+				continue
+			}
+
+			parameter := specParameter{schema: parameterObject["schema"]}
+			parameter.name, _ = parameterObject["name"].(string)
+			parameter.in, _ = parameterObject["in"].(string)
+			parameter.required, _ = parameterObject["required"].(bool)
+
+			parameters = slices.DeleteFunc(parameters, func(other specParameter) bool {
+				return other.name == parameter.name && other.in == parameter.in
+			})
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	return parameters
+}
+
+// resolve follows $ref pointers within the document e.g #/components/schemas/Task
+func (spec *openAPISpec) resolve(node any) any {
+	for depth := 0; depth < 32; depth++ {
+		object, ok := node.(map[string]any)
+		if !ok {
+			return node
+		}
+
+		ref, ok := object["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return node
+		}
+
+		var target any = spec.root
+		for _, key := range strings.Split(ref[2:], "/") {
+			key = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+
+			targetObject, ok := target.(map[string]any)
+			if !ok {
+				return nil
+			}
+			target = targetObject[key]
+		}
+		node = target
+	}
+
+	return node
+}
+
+// match finds the operation of the request and returns it with the values of its path parameters.
+func (spec *openAPISpec) match(req *Request) (*specOperation, map[string]string) {
+	rawPath, _, _ := strings.Cut(req.path, "?")
+
+	path, err := normalizePath(rawPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	for _, method := range []string{req.method, get} {
+		for _, operation := range spec.operations {
+			if operation.method != method {
+				continue
+			}
+
+			values := operation.template.FindStringSubmatch(path)
+			if values == nil {
+				continue
+			}
+
+			pathValues := map[string]string{}
+			for idx, name := range operation.paramNames {
+				pathValues[name] = unescapeSegment(values[idx+1])
+			}
+
+			return operation, pathValues
+		}
+
+		// HEAD requests are handled by GET routes, so they are checked against GET operations.
+		if req.method != head {
+			break
+		}
+	}
+
+	return nil, nil
+}
+
+// validateParameters checks the parameters of the operation against the request.
+func (spec *openAPISpec) validateParameters(req *Request, operation *specOperation, pathValues map[string]string) ValidationErrors {
+	errs := ValidationErrors{}
+
+	for _, parameter := range operation.parameters {
+		var raw string
+		var exists bool
+
+		switch parameter.in {
+		case sourcePath:
+			raw, exists = pathValues[parameter.name]
+		case sourceQuery:
+			raw, exists = req.QueryParams().Get(parameter.name)
+		case sourceHeader:
+			raw, exists = req.header(parameter.name)
+		case sourceCookie:
+			var cookie *Cookie
+			if cookie, exists = req.Cookie(parameter.name); exists {
+				raw = cookie.Value
+			}
+		}
+
+		validation := schemaValidation{spec: spec, req: req, source: parameter.in}
+
+		if !exists {
+			if parameter.required || parameter.in == sourcePath {
+				validation.fail("required", parameter.name, "is required")
+			}
+		} else {
+			validation.validateParameter(parameter.schema, raw, parameter.name)
+		}
+
+		errs = append(errs, validation.errs...)
+	}
+
+	return errs
+}
+
+// validateBody checks the request body against the schema of its media type.
+// It returns the status to respond with if the body is invalid: 400 for malformed JSON, 415 or 422.
+func (spec *openAPISpec) validateBody(req *Request, operation *specOperation) (int, ValidationErrors) {
+	if operation.requestBody == nil {
+		return 0, nil
+	}
+
+	validation := schemaValidation{spec: spec, req: req, source: sourceBody}
+
+	if len(req.body) == 0 {
+		if required, _ := operation.requestBody["required"].(bool); required {
+			validation.fail("required", "", "the request body is required")
+			return status.HTTP_422_UNPROCESSABLE_ENTITY, validation.errs
+		}
+		return 0, nil
+	}
+
+	content, _ := operation.requestBody["content"].(map[string]any)
+	contentType, _ := req.header("Content-Type")
+
+	mediaType, schema, found := contentSchema(content, normalizeMediaType(contentType))
+	if !found {
+		return status.HTTP_415_UNSUPPORTED_MEDIA_TYPE, nil
+	}
+	if !isJSONMediaType(mediaType) {
+		return 0, nil
+	}
+
+	var body any
+	if err := json.Unmarshal(req.body, &body); err != nil {
+		validation.fail(ruleJSON, "", err.Error())
+		return status.HTTP_400_BAD_REQUEST, validation.errs
+	}
+
+	validation.validate(schema, body, "")
+	if len(validation.errs) > 0 {
+		return status.HTTP_422_UNPROCESSABLE_ENTITY, validation.errs
+	}
+
+	return 0, nil
+}
+
+// validateResponse checks that the status of the response is documented and that its body matches the schema.
+func (spec *openAPISpec) validateResponse(req *Request, operation *specOperation, res IResponse) ValidationErrors {
+	validation := schemaValidation{spec: spec, req: req, source: sourceResponse}
+
+	// Like SetStatus, a response without a status is treated as a 200 response.
+	statusCode := strconv.Itoa(cmp.Or(res.StatusCode(), status.HTTP_200_OK))
+	response, exists := operation.responses[statusCode]
+	if !exists {
+		response, exists = operation.responses[statusCode[:1]+"XX"]
+	}
+	if !exists {
+		response, exists = operation.responses["default"]
+	}
+	if !exists {
+		validation.fail("status", "", fmt.Sprintf("status %v is not documented", statusCode))
+		return validation.errs
+	}
+
+	responseObject, _ := spec.resolve(response).(map[string]any)
+	content, _ := responseObject["content"].(map[string]any)
+	if len(content) == 0 || res.Body() == nil {
+		return nil
+	}
+
+	contentType, _ := res.Headers().Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	mediaType, schema, found := contentSchema(content, normalizeMediaType(contentType))
+	if !found {
+		validation.fail("content", "", fmt.Sprintf("media type %v is not documented", contentType))
+		return validation.errs
+	}
+	if !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	var body any
+	encoded, err := json.Marshal(res.Body())
+	if err == nil {
+		err = json.Unmarshal(encoded, &body)
+	}
+	if err != nil {
+		validation.fail(ruleJSON, "", err.Error())
+		return validation.errs
+	}
+
+	validation.validate(schema, body, "")
+	return validation.errs
+}
+
+// contentSchema finds the media type of a content map matching the media type, and its schema.
+func contentSchema(content map[string]any, mediaType string) (string, any, bool) {
+	if mediaType == "" {
+		mediaType = "application/json"
+	}
+
+	bestRange, specificity := "", -1
+	for mediaRange := range content {
+		normalized := normalizeMediaType(mediaRange)
+		if mediaTypeMatches(normalized, mediaType) && mediaRangeSpecificity(normalized) > specificity {
+			bestRange, specificity = mediaRange, mediaRangeSpecificity(normalized)
+		}
+	}
+
+	if specificity < 0 {
+		return "", nil, false
+	}
+
+	mediaTypeObject, _ := content[bestRange].(map[string]any)
+	return mediaType, mediaTypeObject["schema"], true
+}
+
+// isJSONMediaType checks for application/json and its variants e.g application/vnd.acme.v2+json
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// schemaValidation collects the errors of values checked against JSON schemas of the document.
+type schemaValidation struct {
+	spec   *openAPISpec
+	req    *Request
+	source string
+	errs   ValidationErrors
+}
+
+func (v *schemaValidation) fail(rule string, field string, message string) {
+	v.errs = append(v.errs, FieldError{
+		Field:   field,
+		Source:  v.source,
+		Rule:    rule,
+		Message: v.req.validationMessage(rule, field, message),
+	})
+}
+
+// validateParameter converts a raw parameter value to the type of its schema, then validates it.
+// Arrays are read from comma separated values.
+func (v *schemaValidation) validateParameter(schema any, raw string, field string) {
+	value, ok := v.coerce(schema, raw)
+	if !ok {
+		v.fail(ruleType, field, fmt.Sprintf("%q must be %v", raw, typeDescription(schemaTypes(v.schemaObject(schema)))))
+		return
+	}
+
+	v.validate(schema, value, field)
+}
+
+func (v *schemaValidation) coerce(schema any, raw string) (any, bool) {
+	schemaObject := v.schemaObject(schema)
+	types := schemaTypes(schemaObject)
+
+	if slices.Contains(types, "string") || len(types) == 0 {
+		return raw, true
+	}
+
+	for _, schemaType := range types {
+		switch schemaType {
+		case "integer", "number":
+			if number, err := strconv.ParseFloat(raw, 64); err == nil {
+				return number, true
+			}
+
+		case "boolean":
+			if boolean, err := strconv.ParseBool(raw); err == nil {
+				return boolean, true
+			}
+
+		case "array":
+			items := []any{}
+			for _, part := range strings.Split(raw, ",") {
+				item, ok := v.coerce(schemaObject["items"], strings.TrimSpace(part))
+				if !ok {
+					return nil, false
+				}
+				items = append(items, item)
+			}
+			return items, true
+
+		case "null":
+			if raw == "" {
+				return nil, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (v *schemaValidation) schemaObject(schema any) map[string]any {
+	schemaObject, _ := v.spec.resolve(schema).(map[string]any)
+	return schemaObject
+}
+
+// validate checks a decoded JSON value against the schema, field is the path of the value e.g owner.name or tags[0]
+// The keywords checked are those needed for API payloads: type, enum, const, the bounds of numbers, strings, arrays
+// and objects, pattern, format, properties, required, additionalProperties, items, allOf, anyOf and oneOf.
+func (v *schemaValidation) validate(schema any, value any, field string) {
+	schemaObject := v.schemaObject(schema)
+	if schemaObject == nil {
+		return
+	}
+
+	for _, subschema := range schemaList(schemaObject["allOf"]) {
+		v.validate(subschema, value, field)
+	}
+
+	if subschemas := schemaList(schemaObject["anyOf"]); len(subschemas) > 0 && v.countMatching(subschemas, value) == 0 {
+		v.fail("anyOf", field, "must match at least one of the allowed schemas")
+	}
+	if subschemas := schemaList(schemaObject["oneOf"]); len(subschemas) > 0 && v.countMatching(subschemas, value) != 1 {
+		v.fail("oneOf", field, "must match exactly one of the allowed schemas")
+	}
+
+	types := schemaTypes(schemaObject)
+	if nullable, _ := schemaObject["nullable"].(bool); nullable {
+		types = append(types, "null")
+	}
+
+	if len(types) > 0 && !slices.ContainsFunc(types, func(schemaType string) bool { return typeMatches(schemaType, value) }) {
+		v.fail(ruleType, field, "must be "+typeDescription(types))
+		return
+	}
+
+	if enum, exists := schemaObject["enum"].([]any); exists && !slices.ContainsFunc(enum, func(allowed any) bool { return reflect.DeepEqual(allowed, value) }) {
+		allowed := make([]string, len(enum))
+		for idx, enumValue := range enum {
+			allowed[idx] = fmt.Sprint(enumValue)
+		}
+		v.fail("enum", field, "must be one of "+strings.Join(allowed, ", "))
+	}
+
+	if constValue, exists := schemaObject["const"]; exists && !reflect.DeepEqual(constValue, value) {
+		v.fail("const", field, fmt.Sprintf("must be %v", constValue))
+	}
+
+	switch typedValue := value.(type) {
+	case string:
+		v.validateString(schemaObject, typedValue, field)
+	case float64:
+		v.validateNumber(schemaObject, typedValue, field)
+	case []any:
+		v.validateArray(schemaObject, typedValue, field)
+	case map[string]any:
+		v.validateObject(schemaObject, typedValue, field)
+	}
+}
+
+func (v *schemaValidation) countMatching(subschemas []any, value any) int {
+	count := 0
+
+	for _, subschema := range subschemas {
+		nested := schemaValidation{spec: v.spec, req: v.req, source: v.source}
+		if nested.validate(subschema, value, ""); len(nested.errs) == 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (v *schemaValidation) validateString(schemaObject map[string]any, value string, field string) {
+	length := float64(utf8.RuneCountInString(value))
+
+	if minLength, exists := schemaObject["minLength"].(float64); exists && length < minLength {
+		v.fail("minLength", field, fmt.Sprintf("must be at least %v characters long", minLength))
+	}
+	if maxLength, exists := schemaObject["maxLength"].(float64); exists && length > maxLength {
+		v.fail("maxLength", field, fmt.Sprintf("must be at most %v characters long", maxLength))
+	}
+
+	if pattern, exists := schemaObject["pattern"].(string); exists {
+		compiled := v.spec.patterns[pattern]
+
+		if compiled != nil && !compiled.MatchString(value) {
+			v.fail("pattern", field, "must match "+pattern)
+		}
+	}
+
+	if format, exists := schemaObject["format"].(string); exists && !formatMatches(format, value) {
+		v.fail("format", field, "must be a valid "+format)
+	}
+}
+
+func (v *schemaValidation) validateNumber(schemaObject map[string]any, value float64, field string) {
+	if minimum, exists := schemaObject["minimum"].(float64); exists {
+		// OpenAPI 3.0 sets exclusiveMinimum to true instead of a number.
+		if exclusive, _ := schemaObject["exclusiveMinimum"].(bool); exclusive && value <= minimum {
+			v.fail("exclusiveMinimum", field, fmt.Sprintf("must be greater than %v", minimum))
+		} else if value < minimum {
+			v.fail("minimum", field, fmt.Sprintf("must be at least %v", minimum))
+		}
+	}
+	if maximum, exists := schemaObject["maximum"].(float64); exists {
+		if exclusive, _ := schemaObject["exclusiveMaximum"].(bool); exclusive && value >= maximum {
+			v.fail("exclusiveMaximum", field, fmt.Sprintf("must be less than %v", maximum))
+		} else if value > maximum {
+			v.fail("maximum", field, fmt.Sprintf("must be at most %v", maximum))
+		}
+	}
+
+	if exclusiveMinimum, exists := schemaObject["exclusiveMinimum"].(float64); exists && value <= exclusiveMinimum {
+		v.fail("exclusiveMinimum", field, fmt.Sprintf("must be greater than %v", exclusiveMinimum))
+	}
+	if exclusiveMaximum, exists := schemaObject["exclusiveMaximum"].(float64); exists && value >= exclusiveMaximum {
+		v.fail("exclusiveMaximum", field, fmt.Sprintf("must be less than %v", exclusiveMaximum))
+	}
+
+	if multipleOf, exists := schemaObject["multipleOf"].(float64); exists && multipleOf > 0 {
+		if quotient := value / multipleOf; quotient != math.Trunc(quotient) {
+			v.fail("multipleOf", field, fmt.Sprintf("must be a multiple of %v", multipleOf))
+		}
+	}
+}
+
+func (v *schemaValidation) validateArray(schemaObject map[string]any, value []any, field string) {
+	length := float64(len(value))
+
+	if minItems, exists := schemaObject["minItems"].(float64); exists && length < minItems {
+		v.fail("minItems", field, fmt.Sprintf("must have at least %v items", minItems))
+	}
+	if maxItems, exists := schemaObject["maxItems"].(float64); exists && length > maxItems {
+		v.fail("maxItems", field, fmt.Sprintf("must have at most %v items", maxItems))
+	}
+
+	if items, exists := schemaObject["items"]; exists {
+		for idx, item := range value {
+			v.validate(items, item, fmt.Sprintf("%v[%v]", field, idx))
+		}
+	}
+}
+
+func (v *schemaValidation) validateObject(schemaObject map[string]any, value map[string]any, field string) {
+	properties, _ := schemaObject["properties"].(map[string]any)
+
+	if required, exists := schemaObject["required"].([]any); exists {
+		for _, name := range required {
+			name, _ := name.(string)
+			if _, present := value[name]; !present {
+				v.fail("required", joinField(field, name), "is required")
+			}
+		}
+	}
+
+	// Keys are sorted so errors are reported in a stable order.
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if propertySchema, exists := properties[key]; exists {
+			v.validate(propertySchema, value[key], joinField(field, key))
+			continue
+		}
+
+		switch additional := schemaObject["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail("additionalProperties", joinField(field, key), "is not allowed")
+			}
+		case map[string]any:
+			v.validate(additional, value[key], joinField(field, key))
+		}
+	}
+}
+
+func joinField(field string, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// schemaTypes returns the types allowed by a schema, its type keyword can be a string or a list.
+func schemaTypes(schemaObject map[string]any) []string {
+	switch schemaType := schemaObject["type"].(type) {
+	case string:
+		return []string{schemaType}
+	case []any:
+		types := []string{}
+		for _, item := range schemaType {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+
+	return nil
+}
+
+func schemaList(node any) []any {
+	list, _ := node.([]any)
+	return list
+}
+
+func typeMatches(schemaType string, value any) bool {
+	switch schemaType {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	}
+
+	return false
+}
+
+// typeDescription describes schema types for error messages e.g "an integer" or "a string or null"
+func typeDescription(types []string) string {
+	descriptions := make([]string, len(types))
+
+	for idx, schemaType := range types {
+		switch schemaType {
+		case "null":
+			descriptions[idx] = "null"
+		case "integer", "object", "array":
+			descriptions[idx] = "an " + schemaType
+		default:
+			descriptions[idx] = "a " + schemaType
+		}
+	}
+
+	return strings.Join(descriptions, " or ")
+}
+
+// Matches uuids, in any version.
+var uuidFormat = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// formatMatches checks the common string formats, unknown formats are accepted as allowed by JSON schema.
+func formatMatches(format string, value string) bool {
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "uuid":
+		return uuidFormat.MatchString(value)
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "uri", "url":
+		parsed, err := url.Parse(value)
+		return err == nil && parsed.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() == nil
+	}
+
+	return true
+}
@@ -0,0 +1,69 @@
+package goserve
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+const patternDocument = `{
+	"openapi": "3.1.0",
+	"paths": {
+		"/tasks": {
+			"post": {
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Task"}}}
+				},
+				"responses": {"201": {"description": "created"}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Task": {
+				"type": "object",
+				"required": ["slug"],
+				"properties": {"slug": {"type": "string", "pattern": "^[a-z-]+$"}}
+			}
+		}
+	}
+}`
+
+func TestOpenAPIValidationConcurrentPatterns(t *testing.T) {
+	validation, err := OpenAPIValidationMiddleware([]byte(patternDocument), OpenAPIValidationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(Config{})
+	server.AddMiddleWares(validation)
+	server.POST("/tasks", func(req *Request, res IResponse) IResponse {
+		return res.SetStatus(201).Send(nil)
+	})
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < 50; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+
+			slug, want := "valid-slug", 201
+			if idx%2 == 0 {
+				slug, want = "Invalid Slug", 422
+			}
+
+			body := fmt.Sprintf(`{"slug": %q}`, slug)
+			req, err := NewRequest(fmt.Sprintf("POST /tasks HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/json\r\n\r\n%v", body), nil, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if got := server.HandleRequest(req).StatusCode(); got != want {
+				t.Errorf("slug %q: got status %v, want %v", slug, got, want)
+			}
+		}(idx)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,89 @@
+package goserve
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Fuad28/GOServe.git/goserve/utils"
+)
+
+// Params holds path or query parameters.
+// It's a *utils.KeyValueStore[string, string] with typed accessors e.g req.PathParams().Int("id")
+type Params struct {
+	*utils.KeyValueStore[string, string]
+}
+
+// NewParams initializes and returns a pointer to an empty Params instance.
+func NewParams() *Params {
+	return &Params{utils.NewKeyValueStore[string, string]()}
+}
+
+// Int returns the named parameter converted to an int.
+// An error is returned if the parameter doesn't exist or isn't a valid int.
+func (p *Params) Int(key string) (int, error) {
+	value, err := p.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %v: %q is not a valid integer", key, value)
+	}
+
+	return parsed, nil
+}
+
+// Int64 returns the named parameter converted to an int64.
+func (p *Params) Int64(key string) (int64, error) {
+	value, err := p.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %v: %q is not a valid integer", key, value)
+	}
+
+	return parsed, nil
+}
+
+// Float64 returns the named parameter converted to a float64.
+func (p *Params) Float64(key string) (float64, error) {
+	value, err := p.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %v: %q is not a valid number", key, value)
+	}
+
+	return parsed, nil
+}
+
+// Bool returns the named parameter converted to a bool, accepting the values understood by strconv.ParseBool.
+func (p *Params) Bool(key string) (bool, error) {
+	value, err := p.lookup(key)
+	if err != nil {
+		return false, err
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("parameter %v: %q is not a valid boolean", key, value)
+	}
+
+	return parsed, nil
+}
+
+func (p *Params) lookup(key string) (string, error) {
+	value, exists := p.Get(key)
+	if !exists {
+		return "", fmt.Errorf("parameter %v not found", key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,157 @@
+package goserve
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Route paths are made of static text, parameters and wildcards:
+//
+//	/tasks/:id              parameter, matches a whole segment
+//	/tasks/:id<int>         parameter with a named type constraint, see paramTypes
+//	/users/:slug<[a-z0-9-]+> parameter with a regular expression constraint
+//	/files/:name.:ext       parameters sharing a segment, separated by static text
+//	/files/*/meta           single segment wildcard
+//	/files/*filepath        catch-all parameter, matches the rest of the path
+//
+// A ":" starts a parameter at the start of a segment or after a character that isn't a letter or digit,
+// so /v1:batch is static text while /files/:name.:ext holds two parameters.
+
+// Kinds of the tokens a route pattern is made of.
+const (
+	staticToken = iota
+	paramToken
+	wildcardToken
+	catchAllToken
+)
+
+// patternToken is a piece of a route pattern.
+type patternToken struct {
+	kind int
+
+	// The static text for static tokens, or the parameter name.
+	text string
+
+	// The constraint as written in the pattern e.g int or [a-z]+, and its compiled form.
+	constraint string
+	regexp     *regexp.Regexp
+}
+
+// Named constraints usable in place of a regular expression e.g /tasks/:id<int>
+var paramTypes = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[A-Za-z]+`,
+	"alnum": `[A-Za-z0-9]+`,
+	"slug":  `[a-z0-9]+(?:-[a-z0-9]+)*`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// Utility function to split a normalized route pattern into tokens.
+// It returns an error describing the problem if the pattern is invalid.
+func tokenizePattern(pattern string) ([]patternToken, error) {
+	tokens := []patternToken{}
+	static := strings.Builder{}
+
+	flushStatic := func() {
+		if static.Len() > 0 {
+			tokens = append(tokens, patternToken{kind: staticToken, text: static.String()})
+			static.Reset()
+		}
+	}
+
+	for idx := 0; idx < len(pattern); {
+		char := pattern[idx]
+		segmentStart := idx > 0 && pattern[idx-1] == '/'
+
+		switch {
+		case char == '*' && segmentStart:
+			flushStatic()
+			end := strings.IndexByte(pattern[idx:], '/')
+
+			if end == 1 {
+				tokens = append(tokens, patternToken{kind: wildcardToken, text: wildcardParam})
+				idx += end
+				continue
+			}
+			if end < 0 && idx == len(pattern)-1 {
+				tokens = append(tokens, patternToken{kind: wildcardToken, text: wildcardParam})
+				idx++
+				continue
+			}
+			if end > 0 {
+				return nil, fmt.Errorf("invalid path %q: catch-all parameters must be at the end of the path", pattern)
+			}
+
+			tokens = append(tokens, patternToken{kind: catchAllToken, text: pattern[idx+1:]})
+			idx = len(pattern)
+
+		case char == ':' && idx > 0 && (segmentStart || !isAlphanumeric(pattern[idx-1])):
+			if len(tokens) > 0 && static.Len() == 0 && tokens[len(tokens)-1].kind != staticToken {
+				return nil, fmt.Errorf("invalid path %q: parameters must be separated by static text", pattern)
+			}
+			flushStatic()
+
+			token, end, err := parseParamToken(pattern, idx)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token)
+			idx = end
+
+		default:
+			static.WriteByte(char)
+			idx++
+		}
+	}
+	flushStatic()
+
+	return tokens, nil
+}
+
+// parseParamToken parses the parameter starting at pattern[start] i.e :name or :name<constraint>
+// and returns it with the index following it.
+func parseParamToken(pattern string, start int) (patternToken, int, error) {
+	end := start + 1
+	for end < len(pattern) && (isAlphanumeric(pattern[end]) || pattern[end] == '_') {
+		end++
+	}
+
+	token := patternToken{kind: paramToken, text: pattern[start+1 : end]}
+	if token.text == "" {
+		return token, end, fmt.Errorf("invalid path %q: parameters must be named", pattern)
+	}
+
+	if end == len(pattern) || pattern[end] != '<' {
+		return token, end, nil
+	}
+
+	closing := strings.IndexByte(pattern[end:], '>')
+	if closing < 0 {
+		return token, end, fmt.Errorf("invalid path %q: constraint of :%v isn't closed with >", pattern, token.text)
+	}
+
+	token.constraint = pattern[end+1 : end+closing]
+	if strings.Contains(token.constraint, "/") {
+		return token, end, fmt.Errorf("invalid path %q: constraint of :%v can't match /", pattern, token.text)
+	}
+
+	expression := token.constraint
+	if namedExpression, exists := paramTypes[expression]; exists {
+		expression = namedExpression
+	}
+
+	compiled, err := regexp.Compile("^(?:" + expression + ")$")
+	if err != nil {
+		return token, end, fmt.Errorf("invalid path %q: constraint of :%v: %v", pattern, token.text, err.Error())
+	}
+	token.regexp = compiled
+
+	return token, end + closing + 1, nil
+}
+
+func isAlphanumeric(char byte) bool {
+	return ('a' <= char && char <= 'z') || ('A' <= char && char <= 'Z') || ('0' <= char && char <= '9')
+}
@@ -0,0 +1,207 @@
+package goserve
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// forwardedHop is one entry of the Forwarded or X-Forwarded-* headers.
+// It holds what a proxy saw when it received the request: the address it came from, the scheme and the host requested.
+type forwardedHop struct {
+	addr  string
+	proto string
+	host  string
+}
+
+// AddTrustedProxies is used to add proxies whose forwarding headers are trusted, given as CIDRs or single IPs.
+// Forwarded, X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host are ignored for requests not coming from a trusted proxy.
+func (s *Server) AddTrustedProxies(proxies []string) error {
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %v", proxy, err.Error())
+		}
+
+		s.trustedProxies = append(s.trustedProxies, ipNet)
+	}
+
+	return nil
+}
+
+func (s *Server) TrustedProxies() []*net.IPNet {
+	return s.trustedProxies
+}
+
+// isTrustedProxy checks if addr, an IP with or without a port, belongs to a trusted proxy.
+func (s *Server) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(stripPort(addr))
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range s.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RealIP returns the IP of the client that made the request.
+// When the request comes through trusted proxies, the forwarding headers are walked from right to left and
+// the first address that isn't a trusted proxy is returned.
+func (req *Request) RealIP() string {
+	return req.resolveClient().addr
+}
+
+// Scheme returns the scheme (http or https) the client used, as forwarded by trusted proxies.
+func (req *Request) Scheme() string {
+	return req.resolveClient().proto
+}
+
+// ExternalHost returns the host the client requested, as forwarded by trusted proxies, it may include a port.
+func (req *Request) ExternalHost() string {
+	return req.resolveClient().host
+}
+
+// resolveClient finds the hop at which the request entered the trusted network.
+func (req *Request) resolveClient() forwardedHop {
+	peer := forwardedHop{proto: "http"}
+
+	if req.clientAddr != nil {
+		peer.addr = req.clientAddr.IP.String()
+	}
+	if req.host != nil {
+		peer.host = req.host.Host
+	}
+
+	if req.server == nil || !req.server.isTrustedProxy(peer.addr) {
+		return peer
+	}
+
+	hops := req.forwardedHops()
+
+	// The right-most entry was added by the proxy closest to us, so walk from right to left.
+	for idx := len(hops) - 1; idx >= 0; idx-- {
+		hop := hops[idx]
+
+		if hop.proto == "" {
+			hop.proto = peer.proto
+		}
+		if hop.host == "" {
+			hop.host = peer.host
+		}
+		hop.addr = stripPort(hop.addr)
+		peer = hop
+
+		if !req.server.isTrustedProxy(hop.addr) {
+			break
+		}
+	}
+
+	return peer
+}
+
+// forwardedHops parses the Forwarded header, falling back to the X-Forwarded-* headers if it's not set.
+func (req *Request) forwardedHops() []forwardedHop {
+	if forwarded, exists := req.header("Forwarded"); exists {
+		return parseForwarded(forwarded)
+	}
+
+	forwardedFor, exists := req.header("X-Forwarded-For")
+	if !exists {
+		return nil
+	}
+
+	addrs := splitHeaderList(forwardedFor)
+	forwardedProto, _ := req.header("X-Forwarded-Proto")
+	forwardedHost, _ := req.header("X-Forwarded-Host")
+	protos := splitHeaderList(forwardedProto)
+	hosts := splitHeaderList(forwardedHost)
+
+	hops := make([]forwardedHop, len(addrs))
+	for idx, addr := range addrs {
+		hops[idx] = forwardedHop{
+			addr:  addr,
+			proto: alignedValue(protos, idx, len(addrs)),
+			host:  alignedValue(hosts, idx, len(addrs)),
+		}
+	}
+
+	return hops
+}
+
+// alignedValue returns the value matching the idx-th address when a header lists one value per hop.
+// Headers usually hold a single value set by the edge proxy, which applies to every hop.
+func alignedValue(values []string, idx int, count int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if len(values) == count {
+		return values[idx]
+	}
+
+	return values[0]
+}
+
+// Utility function to parse the Forwarded header as defined in RFC 7239 e.g
+// Forwarded: for=192.0.2.60;proto=https;host=example.com, for="[2001:db8:cafe::17]:4711"
+func parseForwarded(header string) []forwardedHop {
+	hops := []forwardedHop{}
+
+	for _, element := range strings.Split(header, ",") {
+		hop := forwardedHop{}
+
+		for _, pair := range strings.Split(element, ";") {
+			key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if !found {
+				continue
+			}
+			value = strings.Trim(value, `"`)
+
+			switch strings.ToLower(key) {
+			case "for":
+				hop.addr = value
+			case "proto":
+				hop.proto = strings.ToLower(value)
+			case "host":
+				hop.host = value
+			}
+		}
+
+		hops = append(hops, hop)
+	}
+
+	return hops
+}
+
+func splitHeaderList(header string) []string {
+	values := []string{}
+
+	for _, value := range strings.Split(header, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// stripPort removes the port and IPv6 brackets from an address e.g [2001:db8::1]:4711 -> 2001:db8::1
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return strings.Trim(addr, "[]")
+}
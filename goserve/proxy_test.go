@@ -0,0 +1,158 @@
+package goserve
+
+import (
+	"net"
+	"testing"
+)
+
+// proxiedRequest reads the raw request as sent by the peer, on a server trusting 10.0.0.0/8 and 2001:db8::/32.
+func proxiedRequest(t *testing.T, peer string, headers string) *Request {
+	t.Helper()
+
+	server := NewServer(Config{TrustedProxies: []string{"10.0.0.0/8", "2001:db8::/32"}})
+
+	req, err := NewRequest("GET / HTTP/1.1\r\nHost: internal:8000\r\n"+headers+"\r\n", &net.TCPAddr{IP: net.ParseIP(peer), Port: 4000}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.server = server
+
+	return req
+}
+
+func TestResolveClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		peer    string
+		headers string
+		ip      string
+		scheme  string
+		host    string
+	}{
+		{
+			name:    "headers from an untrusted peer are ignored",
+			peer:    "203.0.113.9",
+			headers: "X-Forwarded-For: 198.51.100.1\r\nX-Forwarded-Proto: https\r\nX-Forwarded-Host: example.com\r\n",
+			ip:      "203.0.113.9", scheme: "http", host: "internal:8000",
+		},
+		{
+			name: "no forwarding headers from a trusted peer",
+			peer: "10.0.0.1",
+			ip:   "10.0.0.1", scheme: "http", host: "internal:8000",
+		},
+		{
+			name:    "the walk stops at the first untrusted hop",
+			peer:    "10.0.0.1",
+			headers: "X-Forwarded-For: 198.51.100.1, 203.0.113.7, 10.0.0.2\r\n",
+			ip:      "203.0.113.7", scheme: "http", host: "internal:8000",
+		},
+		{
+			name:    "a spoofed left-most entry isn't used",
+			peer:    "10.0.0.1",
+			headers: "X-Forwarded-For: 127.0.0.1, 203.0.113.7\r\n",
+			ip:      "203.0.113.7", scheme: "http", host: "internal:8000",
+		},
+		{
+			name:    "only trusted hops gives the left-most one",
+			peer:    "10.0.0.1",
+			headers: "X-Forwarded-For: 10.0.0.3, 10.0.0.2\r\n",
+			ip:      "10.0.0.3", scheme: "http", host: "internal:8000",
+		},
+		{
+			name:    "single X-Forwarded-Proto and X-Forwarded-Host apply to every hop",
+			peer:    "10.0.0.1",
+			headers: "X-Forwarded-For: 203.0.113.7, 10.0.0.2\r\nX-Forwarded-Proto: https\r\nX-Forwarded-Host: example.com\r\n",
+			ip:      "203.0.113.7", scheme: "https", host: "example.com",
+		},
+		{
+			name:    "per hop X-Forwarded-Proto and X-Forwarded-Host",
+			peer:    "10.0.0.1",
+			headers: "X-Forwarded-For: 203.0.113.7, 10.0.0.2\r\nX-Forwarded-Proto: https, http\r\nX-Forwarded-Host: example.com, edge.internal\r\n",
+			ip:      "203.0.113.7", scheme: "https", host: "example.com",
+		},
+		{
+			name:    "Forwarded with a quoted IPv6 address and a port",
+			peer:    "10.0.0.1",
+			headers: "Forwarded: for=\"[2001:db9:cafe::17]:4711\";proto=https;host=example.com, for=10.0.0.2\r\n",
+			ip:      "2001:db9:cafe::17", scheme: "https", host: "example.com",
+		},
+		{
+			name:    "Forwarded takes precedence over X-Forwarded-For",
+			peer:    "2001:db8::1",
+			headers: "Forwarded: for=198.51.100.1\r\nX-Forwarded-For: 203.0.113.7\r\n",
+			ip:      "198.51.100.1", scheme: "http", host: "internal:8000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := proxiedRequest(t, test.peer, test.headers)
+
+			if ip := req.RealIP(); ip != test.ip {
+				t.Errorf("got IP %v, want %v", ip, test.ip)
+			}
+			if scheme := req.Scheme(); scheme != test.scheme {
+				t.Errorf("got scheme %v, want %v", scheme, test.scheme)
+			}
+			if host := req.ExternalHost(); host != test.host {
+				t.Errorf("got host %v, want %v", host, test.host)
+			}
+		})
+	}
+}
+
+func TestIsSameOriginThroughProxies(t *testing.T) {
+	tests := []struct {
+		name     string
+		peer     string
+		headers  string
+		expected bool
+	}{
+		{"origin of the forwarded scheme and host", "10.0.0.1", "Origin: https://example.com\r\nX-Forwarded-For: 203.0.113.7\r\nX-Forwarded-Proto: https\r\nX-Forwarded-Host: example.com\r\n", true},
+		{"forwarded headers of an untrusted peer", "203.0.113.9", "Origin: https://example.com\r\nX-Forwarded-Proto: https\r\nX-Forwarded-Host: example.com\r\n", false},
+		{"origin of the Host header", "203.0.113.9", "Origin: http://internal:8000\r\n", true},
+		{"scheme differing from the forwarded one", "10.0.0.1", "Origin: http://example.com\r\nX-Forwarded-For: 203.0.113.7\r\nX-Forwarded-Proto: https\r\nX-Forwarded-Host: example.com\r\n", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := proxiedRequest(t, test.peer, test.headers)
+
+			if sameOrigin := isSameOrigin(req); sameOrigin != test.expected {
+				t.Errorf("got same origin %v, want %v", sameOrigin, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseForwarded(t *testing.T) {
+	hops := parseForwarded(`for=192.0.2.60;proto=HTTPS;host=example.com, For="[2001:db8:cafe::17]:4711"`)
+
+	want := []forwardedHop{
+		{addr: "192.0.2.60", proto: "https", host: "example.com"},
+		{addr: "[2001:db8:cafe::17]:4711"},
+	}
+	if len(hops) != len(want) || hops[0] != want[0] || hops[1] != want[1] {
+		t.Errorf("got hops %+v, want %+v", hops, want)
+	}
+}
+
+func TestAlignedValue(t *testing.T) {
+	tests := []struct {
+		values []string
+		idx    int
+		count  int
+		want   string
+	}{
+		{nil, 0, 2, ""},
+		{[]string{"https"}, 1, 2, "https"},
+		{[]string{"https", "http"}, 1, 2, "http"},
+		{[]string{"https", "http"}, 2, 3, "https"},
+	}
+
+	for _, test := range tests {
+		if value := alignedValue(test.values, test.idx, test.count); value != test.want {
+			t.Errorf("got %q for %v at %v of %v, want %q", value, test.values, test.idx, test.count, test.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package goserve
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+	"github.com/Fuad28/GOServe.git/goserve/utils"
+)
+
+// Signature for the hook reporting panics recovered while handling requests e.g to send them to an error tracker.
+// recovered is the value passed to panic() and stack the stack trace of the goroutine when it panicked.
+// The request only holds the client and server addresses if the panic happened while it was being parsed.
+type PanicHandlerFunc func(req *Request, recovered any, stack []byte)
+
+// OnPanic sets the hook called with the panics recovered while handling requests.
+// Panics are always logged and answered with a 500 response, the hook is only told about them.
+// It is called on the goroutine of the request, so it shouldn't block.
+func (s *Server) OnPanic(handler PanicHandlerFunc) {
+	s.panicHandler = handler
+}
+
+// recoverPanic recovers a panic of the goroutine handling req and sets *res to a 500 response.
+// It must be deferred, e.g defer s.recoverPanic(req, &res)
+func (s *Server) recoverPanic(req *Request, res *IResponse) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	*res = s.handlePanic(req, recovered, debug.Stack())
+}
+
+// recoverMiddleware recovers the panics of the route middlewares and handler, and answers them with a 500 response.
+// It runs after the server middlewares so they handle that response as any other, e.g the access log writes it.
+// The headers and cookies set before it, e.g CORS headers, are kept on the response.
+// Panics of the server middlewares are recovered by HandleRequest.
+func (s *Server) recoverMiddleware(req *Request, res IResponse) (response IResponse) {
+	headers := maps.Clone(res.Headers().GetAll())
+	cookies := slices.Clone(res.Cookies())
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		response = s.handlePanic(req, recovered, debug.Stack())
+		for key, value := range headers {
+			// The 500 response has a JSON body, whatever the type negotiated for the route.
+			if !response.Headers().Has(key) && !strings.EqualFold(key, "Content-Type") {
+				response.SetHeader(key, value)
+			}
+		}
+		for _, cookie := range cookies {
+			response.SetCookie(cookie)
+		}
+	}()
+
+	return req.Next(res)
+}
+
+// handlePanic logs the panic, reports it to the panic hook and builds the 500 response.
+// The stack and panic value are only sent to the client when Config.Debug is set.
+func (s *Server) handlePanic(req *Request, recovered any, stack []byte) IResponse {
+	s.Logger().Error("panic recovered", "method", req.method, "path", req.path, "panic", fmt.Sprint(recovered), "stack", string(stack))
+
+	if s.panicHandler != nil {
+		// A panicking hook mustn't take the server down either.
+		func() {
+			defer func() {
+				if hookPanic := recover(); hookPanic != nil {
+					s.Logger().Error("panic in the panic handler", "panic", fmt.Sprint(hookPanic), "stack", string(debug.Stack()))
+				}
+			}()
+
+			s.panicHandler(req, recovered, stack)
+		}()
+	}
+
+	// The response being built may have been partially written, so a new one is sent.
+	res := NewResponse(nil)
+	if req.httpVersion != "" {
+		res = NewResponse(req)
+	}
+	body := JSON{"error": "Internal server error."}
+
+	if s.config.Debug {
+		body["panic"] = fmt.Sprint(recovered)
+		body["stack"] = string(stack)
+	}
+
+	return res.SetStatus(status.HTTP_500_INTERNAL_SERVER_ERROR).Send(body)
+}
+
+// newBareRequest returns a request only holding the client and server addresses,
+// used for requests that couldn't be read or parsed.
+func (s *Server) newBareRequest(clientAddr *net.TCPAddr) *Request {
+	return &Request{
+		clientAddr: clientAddr,
+		serverAddr: s.addr,
+		headers:    utils.NewKeyValueStore[string, string](),
+		Store:      utils.NewKeyValueStore[any, any](),
+		server:     s,
+		ctx:        s.ctx,
+	}
+}
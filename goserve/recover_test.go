@@ -0,0 +1,34 @@
+package goserve
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestPanicResponseGoesThroughServerMiddlewares(t *testing.T) {
+	var accessLog bytes.Buffer
+
+	server := NewServer(Config{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	server.AddMiddleWares(
+		AccessLogMiddleware(AccessLogConfig{Output: &accessLog}),
+		CORSMiddlewareWithConfig(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}),
+	)
+	server.GET("/panic", func(req *Request, res IResponse) IResponse { panic("boom") })
+
+	res := sendRequest(t, server, "GET /panic HTTP/1.1\r\nHost: localhost\r\nOrigin: https://app.example.com\r\n\r\n")
+	if res.StatusCode() != 500 {
+		t.Errorf("got status %v, want 500", res.StatusCode())
+	}
+	if origin, _ := res.Headers().Get("Access-Control-Allow-Origin"); origin != "https://app.example.com" {
+		t.Errorf("got Access-Control-Allow-Origin %q, want the CORS headers kept", origin)
+	}
+	if requestID, _ := res.Headers().Get("X-Request-ID"); requestID == "" {
+		t.Error("got no X-Request-ID header, want the one set by the access log")
+	}
+	if line := accessLog.String(); !strings.Contains(line, `"GET /panic HTTP/1.1" 500`) {
+		t.Errorf("got access log %q, want the 500 response logged", line)
+	}
+}
@@ -2,10 +2,10 @@ package goserve
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 	"net/url"
 	"reflect"
@@ -58,17 +58,41 @@ type Request struct {
 	// Accessed via Path()
 	path string
 
-	// uses the *utils.KeyValueStore[string, string] data structure to hold path parameters found in the request path.
+	// uses the *Params data structure (a *utils.KeyValueStore[string, string]) to hold path parameters found in the request path.
 	// Accessed via PathParams()
-	pathParams *utils.KeyValueStore[string, string]
+	pathParams *Params
 
-	// uses the *utils.KeyValueStore[string, string] data structure to hold query parameters found in the request path.
+	// The media type negotiated with the Accept header when the route declares the types it produces.
+	// Accessed via MediaType()
+	mediaType string
+
+	// uses the *Params data structure (a *utils.KeyValueStore[string, string]) to hold query parameters found in the request path.
+	// It's parsed from rawQuery the first time it's accessed.
 	// Accessed via QueryParams()
-	queryParams *utils.KeyValueStore[string, string]
+	queryParams *Params
+
+	// The part of the request target after "?".
+	rawQuery string
+
+	// The middlewares and handler the request is passed through by Next(), in order.
+	// It's a pointer so the copies made by WithContext() share the progress through the chain.
+	handlerChain *handlerChain
+
+	// The route matched by the request, nil if none did e.g for 404 responses.
+	// Accessed via Route()
+	route *Route
 
-	// uses the *utils.Queue[HandlerFunc] to hold the entire handlers chain for the request.
-	// While the request is being handled, the middlewares and handler are put in a queue to preserve order and allow for efficient retrieval.
-	handlerChain *utils.Queue[HandlerFunc]
+	// Holds the cookies parsed from the Cookie header.
+	// Accessed via Cookies() and Cookie(name)
+	cookies []*Cookie
+
+	// The server handling the request, set when the request is passed to HandleRequest.
+	// Gives access to server-wide settings such as the secret key for signed cookies.
+	server *Server
+
+	// ctx is cancelled when the client disconnects, the server shuts down or the handler deadline is exceeded.
+	// Accessed via Context(), replaced via WithContext()
+	ctx context.Context
 
 	// An empty Store of type *utils.KeyValueStore[string, string] is kept on all requests.
 	// Allows for sotring and passing data throughout the request-response cycle.
@@ -76,7 +100,13 @@ type Request struct {
 }
 
 func NewRequest(req string, clientAddr *net.TCPAddr, serverAddr *net.TCPAddr) (*Request, error) {
-	scanner := bufio.NewScanner(strings.NewReader(req))
+	// The body is kept as sent, only the request line and headers are read line by line.
+	head, body, found := strings.Cut(req, "\r\n\r\n")
+	if !found {
+		head, body, _ = strings.Cut(req, "\n\n")
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(head))
 
 	if !scanner.Scan() {
 		return nil, errors.New("invalid request: missing request line")
@@ -90,8 +120,13 @@ func NewRequest(req string, clientAddr *net.TCPAddr, serverAddr *net.TCPAddr) (*
 
 	// Parse request line
 	requestLine := strings.Fields(scanner.Text())
+	if len(requestLine) != 3 {
+		return nil, errors.New("invalid request: malformed request line")
+	}
+
 	request.method = strings.ToUpper(requestLine[0])
 	request.path = requestLine[1]
+	_, request.rawQuery, _ = strings.Cut(request.path, "?")
 	request.httpVersion = requestLine[2]
 
 	// The curl client sends HEAD request using either -I or --head
@@ -126,25 +161,26 @@ func NewRequest(req string, clientAddr *net.TCPAddr, serverAddr *net.TCPAddr) (*
 	request.headers = headers
 
 	// Parse body
-	bodyStrBuilder := new(strings.Builder)
-	for scanner.Scan() {
-		bodyStrBuilder.WriteString(scanner.Text())
-	}
-
-	bodyStr := strings.Trim(bodyStrBuilder.String(), "")
-
-	if bodyStr != "" {
-		// Validate body is a valid JSON
-		bodyBytes := []byte(bodyStr)
-		var bodyJSON map[string]any
-
-		if err := json.Unmarshal(bodyBytes, &bodyJSON); err != nil {
-			return nil, fmt.Errorf("invalid request: %v", err.Error())
-
-		} else {
-			request.body = bodyBytes
+	// JSON bodies, and bodies sent without a Content-Type, are checked up front so malformed ones get a 400 response.
+	// Bodies of other media types e.g text/csv are kept as they are, read them with RawBody().
+	if body != "" {
+		bodyBytes := []byte(body)
+		contentType, _ := request.header("Content-Type")
+
+		if contentType == "" || isJSONMediaType(normalizeMediaType(contentType)) {
+			var bodyJSON any
+			if err := json.Unmarshal(bodyBytes, &bodyJSON); err != nil {
+				return nil, fmt.Errorf("invalid request: %v", err.Error())
+			}
 		}
 
+		request.body = bodyBytes
+	}
+
+	// Parse cookies
+	request.cookies = []*Cookie{}
+	if cookieStr, exists := request.header("Cookie"); exists {
+		request.cookies = parseCookies(cookieStr)
 	}
 
 	// Parse Host
@@ -174,9 +210,26 @@ func NewRequest(req string, clientAddr *net.TCPAddr, serverAddr *net.TCPAddr) (*
 	return &request, nil
 }
 
-func (req *Request) Next(res IResponse) IResponse {
-	handler := req.handlerChain.Dequeue().Value
-	return handler(req, res)
+// Context returns the request's context, pass it on to database calls and other long running work.
+// It's never nil, requests created outside the server use context.Background().
+func (req *Request) Context() context.Context {
+	if req.ctx == nil {
+		return context.Background()
+	}
+	return req.ctx
+}
+
+// WithContext returns a shallow copy of the request with its context changed to ctx.
+// The copy shares the handler chain, so a middleware can continue with it: req.WithContext(ctx).Next(res)
+func (req *Request) WithContext(ctx context.Context) *Request {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	newReq := *req
+	newReq.ctx = ctx
+
+	return &newReq
 }
 
 func (req *Request) HTTPVersion() string {
@@ -203,20 +256,33 @@ func (req *Request) Origin() *url.URL {
 	return req.origin
 }
 
+// Body decodes the JSON body into v and validates it using the `valid` tags.
+// Decoding and validation failures are returned as ValidationErrors.
 func (req *Request) Body(v any) error {
 
-	if reflect.TypeOf(v).Kind() != reflect.Pointer {
-		log.Fatal("v must be a point")
+	if v == nil || reflect.TypeOf(v).Kind() != reflect.Pointer {
+		return errors.New("body: v must be a non-nil pointer")
 	}
 
-	err := json.Unmarshal(req.body, v)
+	if err := json.Unmarshal(req.body, v); err != nil {
+		return ValidationErrors{req.jsonFieldError(err)}
+	}
 
-	if err != nil {
-		return err
+	var validationErrs ValidationErrors
+	if _, err := validator.ValidateStruct(v); err != nil {
+		validationErrs = req.validationFieldErrors(err, reflect.TypeOf(v).Elem(), nil)
+	}
+	validationErrs = append(validationErrs, req.customValidationErrors(reflect.ValueOf(v), "", nil)...)
+
+	if len(validationErrs) > 0 {
+		return validationErrs
 	}
-	_, err = validator.ValidateStruct(v)
+	return nil
+}
 
-	return err
+// RawBody returns the body as it was sent, e.g for bodies that aren't JSON.
+func (req *Request) RawBody() []byte {
+	return req.body
 }
 
 func (req *Request) Method() string {
@@ -227,10 +293,84 @@ func (req *Request) Path() string {
 	return req.path
 }
 
-func (req *Request) PathParams() *utils.KeyValueStore[string, string] {
+// PathParams returns the percent-decoded parameters captured from the path by the matched route.
+func (req *Request) PathParams() *Params {
+	if req.pathParams == nil {
+		req.pathParams = NewParams()
+	}
 	return req.pathParams
 }
 
-func (req *Request) QueryParams() *utils.KeyValueStore[string, string] {
+// QueryParams returns the percent-decoded query parameters.
+func (req *Request) QueryParams() *Params {
+	if req.queryParams == nil {
+		req.queryParams = parseQueryParams(req.rawQuery)
+	}
 	return req.queryParams
 }
+
+// Cookies returns all the cookies sent with the request.
+func (req *Request) Cookies() []*Cookie {
+	return req.cookies
+}
+
+// Cookie returns the named cookie sent with the request.
+// If multiple cookies match the given name, only the first one is returned.
+func (req *Request) Cookie(name string) (*Cookie, bool) {
+	for _, cookie := range req.cookies {
+		if cookie.Name == name {
+			return cookie, true
+		}
+	}
+
+	return nil, false
+}
+
+// SignedCookie returns the named cookie after verifying it was set with res.SetSignedCookie.
+// The returned cookie's value has the signature stripped.
+func (req *Request) SignedCookie(name string) (*Cookie, error) {
+	secret, err := req.secretKey()
+	if err != nil {
+		return nil, err
+	}
+
+	cookie, exists := req.Cookie(name)
+	if !exists {
+		return nil, ErrCookieNotFound
+	}
+
+	value, err := verifyCookieValue(secret, name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cookie{Name: name, Value: value}, nil
+}
+
+// EncryptedCookie returns the named cookie after decrypting the value set with res.SetEncryptedCookie.
+func (req *Request) EncryptedCookie(name string) (*Cookie, error) {
+	secret, err := req.secretKey()
+	if err != nil {
+		return nil, err
+	}
+
+	cookie, exists := req.Cookie(name)
+	if !exists {
+		return nil, ErrCookieNotFound
+	}
+
+	value, err := decryptCookieValue(secret, name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cookie{Name: name, Value: value}, nil
+}
+
+func (req *Request) secretKey() ([]byte, error) {
+	if req.server == nil || req.server.config.SecretKey == "" {
+		return nil, ErrMissingSecret
+	}
+
+	return []byte(req.server.config.SecretKey), nil
+}
@@ -3,7 +3,9 @@ package goserve
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strconv"
+	"strings"
 
 	"github.com/Fuad28/GOServe.git/goserve/status"
 	"github.com/Fuad28/GOServe.git/goserve/utils"
@@ -39,6 +41,21 @@ type IResponse interface {
 	// This is invoked in the request-response cycle after a response is ready.
 	// It accepts an isHead bool to know whether to set request body or not.
 	GetResponseByte(bool) []byte
+
+	// Adds a Set-Cookie header for the cookie. Cookies are kept apart from other headers as multiple can be set.
+	// Cookies with an invalid name aren't sent, and bytes that can't be sent are dropped from values, see Cookie.String()
+	// Both are logged with the server logger.
+	SetCookie(Cookie) IResponse
+
+	// Signs the cookie value using the server's SecretKey before adding it, read it back with req.SignedCookie.
+	// The name must be a token and the value made of cookie-octets, ErrInvalidCookieName and ErrInvalidCookie are returned otherwise.
+	SetSignedCookie(Cookie) error
+
+	// Encrypts the cookie value using the server's SecretKey before adding it, read it back with req.EncryptedCookie.
+	SetEncryptedCookie(Cookie) error
+
+	// Gives access to the cookies set on the response.
+	Cookies() []Cookie
 }
 
 // Response is a type that holds response data.
@@ -61,16 +78,33 @@ type Response struct {
 	// Holds the body of the reposne which is expected to be valid JSON serializatble.
 	// Accessed via Body()
 	body any
+
+	// Holds the cookies to be sent as Set-Cookie headers.
+	// Accessed via Cookies()
+	cookies []Cookie
+
+	// Copied from the server config, used to sign and encrypt cookies.
+	secretKey []byte
+
+	// The logger of the server, used to report cookies that can't be sent as set.
+	logger *slog.Logger
 }
 
 func NewResponse(req *Request) *Response {
 	httpVersion := "HTTP/1.1"
+	var secretKey []byte
+	var server *Server
+
 	if req != nil {
 		httpVersion = req.httpVersion
+		secretKey, _ = req.secretKey()
+		server = req.server
 	}
 	return &Response{
 		httpVersion: httpVersion,
 		headers:     utils.NewKeyValueStore[string, string](),
+		secretKey:   secretKey,
+		logger:      server.Logger(),
 	}
 }
 
@@ -107,16 +141,35 @@ func (res *Response) BodyAsString() string {
 	return bodySting
 }
 
+// SetDefaultHeaders sets the Content-Length of the body, and its Content-Type to application/json unless one is set.
 func (res *Response) SetDefaultHeaders(bodyStr string) {
-	res.SetHeader("Content-Type", "application/json")
+	if !res.hasHeader("Content-Type") {
+		res.SetHeader("Content-Type", "application/json")
+	}
 	res.SetHeader("Content-Length", strconv.Itoa(len(bodyStr)))
 }
 
+// hasHeader checks if a non empty value is set for the header, whatever the case its name is set with.
+func (res *Response) hasHeader(name string) bool {
+	for key, value := range res.headers.GetAll() {
+		if strings.EqualFold(key, name) && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (res *Response) HeadersToString() string {
 	var headerString string
 	for key, value := range res.headers.GetAll() {
 		headerString += fmt.Sprintf("%v:%v\r\n", key, value)
 	}
+	for _, cookie := range res.cookies {
+		// Cookies with an invalid name serialize to nothing, they aren't sent.
+		if value := cookie.String(); value != "" {
+			headerString += fmt.Sprintf("Set-Cookie:%v\r\n", value)
+		}
+	}
 	headerString += "\r\n"
 
 	return headerString
@@ -159,6 +212,59 @@ func (res *Response) Send(body any) IResponse {
 	return res
 }
 
+func (res *Response) SetCookie(cookie Cookie) IResponse {
+	if !isCookieSendable(cookie) {
+		// sent is empty when the cookie isn't sent at all.
+		res.logger.Warn("cookie can't be sent as set", "cookie", cookie.Name, "sent", cookie.String())
+	}
+
+	res.cookies = append(res.cookies, cookie)
+	return res
+}
+
+func (res *Response) SetSignedCookie(cookie Cookie) error {
+	if len(res.secretKey) == 0 {
+		return ErrMissingSecret
+	}
+
+	// The signature covers the value as given, so it can't be sanitized when it's sent.
+	if !isCookieNameValid(cookie.Name) {
+		return ErrInvalidCookieName
+	}
+	if !isCookieValueValid(cookie.Value) {
+		return ErrInvalidCookie
+	}
+
+	cookie.Value = signCookieValue(res.secretKey, cookie.Name, cookie.Value)
+	res.SetCookie(cookie)
+
+	return nil
+}
+
+func (res *Response) SetEncryptedCookie(cookie Cookie) error {
+	if len(res.secretKey) == 0 {
+		return ErrMissingSecret
+	}
+
+	if !isCookieNameValid(cookie.Name) {
+		return ErrInvalidCookieName
+	}
+
+	value, err := encryptCookieValue(res.secretKey, cookie.Name, cookie.Value)
+	if err != nil {
+		return err
+	}
+
+	cookie.Value = value
+	res.SetCookie(cookie)
+
+	return nil
+}
+
+func (res *Response) Cookies() []Cookie {
+	return res.cookies
+}
+
 func (res *Response) GetResponseByte(isHead bool) []byte {
 	bodyStr := res.BodyAsString()
 	res.SetDefaultHeaders(bodyStr)
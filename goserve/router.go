@@ -0,0 +1,453 @@
+package goserve
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// The router stores routes in a compressed radix tree per HTTP method.
+// Static text shared by routes is stored once, e.g /tasks and /teams share the /t node:
+//
+//	/t
+//	├── asks
+//	│   └── /
+//	│       └── :id
+//	└── eams
+//
+// Lookups walk the tree one node at a time and backtrack if a branch doesn't lead to a route.
+// Children are tried in order of precedence: static text, :param, * (single segment wildcard) then *name (catch-all).
+// Paths are stored and looked up in their normalized form, see normalizePath.
+type node struct {
+
+	// Static text matched by this node, empty for the root and parameter nodes.
+	prefix string
+
+	// Name of the parameter captured by this node, empty for static nodes.
+	// Single segment wildcards are captured as "*".
+	paramName string
+
+	// Constraint the parameter value must match, as written in the pattern and compiled.
+	constraint string
+	regexp     *regexp.Regexp
+
+	// Set for parameters followed by static text in the same segment e.g :name in /files/:name.:ext
+	// Their value can end before the end of the segment.
+	inSegment bool
+
+	// Children matching static text, they never share a first byte.
+	staticChildren []*node
+
+	// Children capturing a path segment, tried after static children.
+	// Constrained parameters are tried first, then unconstrained ones and the single segment wildcard last,
+	// in registration order otherwise.
+	paramChildren []*node
+
+	// Child capturing the rest of the path, tried last.
+	catchAllChild *node
+
+	// The routes registered for the path ending at this node, if any.
+	// There's more than one when routes are told apart by matchers, see matchers.go.
+	routes []*Route
+}
+
+// Key under which single segment wildcard values are captured.
+const wildcardParam = "*"
+
+// A path parameter captured while walking the tree.
+type pathParam struct {
+	key   string
+	value string
+}
+
+// insert adds a route for the pattern tokens, relative to the node.
+func (n *node) insert(tokens []patternToken, route *Route) {
+	if len(tokens) == 0 {
+		n.routes = append(n.routes, route)
+		return
+	}
+
+	token, rest := tokens[0], tokens[1:]
+
+	switch token.kind {
+	case staticToken:
+		n.insertStatic(token.text, rest, route)
+
+	case catchAllToken:
+		if n.catchAllChild == nil {
+			n.catchAllChild = &node{paramName: token.text}
+		}
+		n.catchAllChild.insert(rest, route)
+
+	default:
+		child := n.paramChild(token)
+		if len(rest) > 0 && rest[0].kind == staticToken && rest[0].text[0] != '/' {
+			child.inSegment = true
+		}
+		child.insert(rest, route)
+	}
+}
+
+// paramChild returns the child for the parameter or wildcard token, creating it if needed.
+func (n *node) paramChild(token patternToken) *node {
+	for _, child := range n.paramChildren {
+		if child.paramName == token.text && child.constraint == token.constraint {
+			return child
+		}
+	}
+
+	child := &node{paramName: token.text, constraint: token.constraint, regexp: token.regexp}
+
+	position := len(n.paramChildren)
+	for position > 0 && paramPrecedence(n.paramChildren[position-1]) > paramPrecedence(child) {
+		position--
+	}
+	n.paramChildren = slices.Insert(n.paramChildren, position, child)
+
+	return child
+}
+
+// paramPrecedence orders parameter children: constrained, unconstrained then wildcard.
+func paramPrecedence(n *node) int {
+	switch {
+	case n.paramName == wildcardParam:
+		return 2
+	case n.regexp == nil:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// insertStatic adds the static text to the node's static children, splitting a child if it only shares part of its prefix.
+func (n *node) insertStatic(static string, rest []patternToken, route *Route) {
+	for idx, child := range n.staticChildren {
+		if child.prefix[0] != static[0] {
+			continue
+		}
+
+		common := commonPrefixLength(child.prefix, static)
+
+		if common < len(child.prefix) {
+			split := &node{
+				prefix:         child.prefix[:common],
+				staticChildren: []*node{child},
+			}
+			child.prefix = child.prefix[common:]
+			n.staticChildren[idx] = split
+			child = split
+		}
+
+		if common == len(static) {
+			child.insert(rest, route)
+		} else {
+			child.insertStatic(static[common:], rest, route)
+		}
+
+		return
+	}
+
+	child := &node{prefix: static}
+	n.staticChildren = append(n.staticChildren, child)
+	child.insert(rest, route)
+}
+
+// remove removes the route from the node ending the pattern tokens, relative to the node.
+// Nodes left without routes are kept, they don't match anything.
+func (n *node) remove(tokens []patternToken, route *Route) {
+	if len(tokens) == 0 {
+		n.routes = slices.DeleteFunc(n.routes, func(other *Route) bool { return other == route })
+		return
+	}
+
+	token, rest := tokens[0], tokens[1:]
+
+	switch token.kind {
+	case staticToken:
+		n.removeStatic(token.text, rest, route)
+
+	case catchAllToken:
+		if n.catchAllChild != nil {
+			n.catchAllChild.remove(rest, route)
+		}
+
+	default:
+		for _, child := range n.paramChildren {
+			if child.paramName == token.text && child.constraint == token.constraint {
+				child.remove(rest, route)
+			}
+		}
+	}
+}
+
+// removeStatic follows the static text through the static children, which may have been split across several nodes.
+func (n *node) removeStatic(static string, rest []patternToken, route *Route) {
+	for _, child := range n.staticChildren {
+		if !strings.HasPrefix(static, child.prefix) {
+			continue
+		}
+
+		if len(static) == len(child.prefix) {
+			child.remove(rest, route)
+		} else {
+			child.removeStatic(static[len(child.prefix):], rest, route)
+		}
+
+		return
+	}
+}
+
+// lookup walks the tree for path, relative to the node, and returns the routes registered for it.
+// Captured parameters are appended to params, nothing is allocated for paths without parameters.
+// ignoreCase compares static text case insensitively.
+func (n *node) lookup(path string, params *[]pathParam, ignoreCase bool) []*Route {
+	if path == "" {
+		if len(n.routes) > 0 {
+			return n.routes
+		}
+
+		// Catch-all parameters also match an empty rest e.g /files/*filepath matches /files/
+		return n.lookupCatchAll(path, params)
+	}
+
+	for _, child := range n.staticChildren {
+		if !hasPathPrefix(path, child.prefix, ignoreCase) {
+			continue
+		}
+
+		if routes := child.lookup(path[len(child.prefix):], params, ignoreCase); routes != nil {
+			return routes
+		}
+	}
+
+	segmentEnd := strings.IndexByte(path, '/')
+	if segmentEnd < 0 {
+		segmentEnd = len(path)
+	}
+
+	for _, child := range n.paramChildren {
+		// Parameters can't be empty, and take the whole segment unless static text follows them in the segment.
+		// The shortest value is tried first, so :name.:ext gives name "archive" and ext "tar.gz" for archive.tar.gz
+		end := segmentEnd
+		if child.inSegment {
+			end = 1
+		}
+
+		for ; 0 < end && end <= segmentEnd; end++ {
+			if child.regexp != nil && !child.regexp.MatchString(unescapeSegment(path[:end])) {
+				continue
+			}
+
+			*params = append(*params, pathParam{key: child.paramName, value: path[:end]})
+
+			if routes := child.lookup(path[end:], params, ignoreCase); routes != nil {
+				return routes
+			}
+
+			*params = (*params)[:len(*params)-1]
+		}
+	}
+
+	return n.lookupCatchAll(path, params)
+}
+
+func (n *node) lookupCatchAll(path string, params *[]pathParam) []*Route {
+	if n.catchAllChild == nil || len(n.catchAllChild.routes) == 0 {
+		return nil
+	}
+
+	*params = append(*params, pathParam{key: n.catchAllChild.paramName, value: path})
+	return n.catchAllChild.routes
+}
+
+func hasPathPrefix(path string, prefix string, ignoreCase bool) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+
+	if ignoreCase {
+		return strings.EqualFold(path[:len(prefix)], prefix)
+	}
+
+	return path[:len(prefix)] == prefix
+}
+
+func commonPrefixLength(a string, b string) int {
+	idx := 0
+	for idx < len(a) && idx < len(b) && a[idx] == b[idx] {
+		idx++
+	}
+
+	return idx
+}
+
+// lookupRoutes finds the routes registered for the method and normalized path in the trees.
+// HEAD requests are also handled by GET routes.
+func lookupRoutes(trees map[string]*node, method string, path string, ignoreCase bool) ([]*Route, []pathParam) {
+	var params []pathParam
+
+	if root, exists := trees[method]; exists {
+		if routes := root.lookup(path, &params, ignoreCase); routes != nil {
+			return routes, params
+		}
+	}
+
+	if method == head {
+		return lookupRoutes(trees, get, path, ignoreCase)
+	}
+
+	return nil, nil
+}
+
+// allowedMethods lists the methods with a route registered for the normalized path in any of the route tables.
+// HEAD is allowed wherever GET is, and OPTIONS wherever any method is, as they are handled automatically.
+func allowedMethods(tables []routeTable, path string, ignoreCase bool) []string {
+	registered := map[string]bool{}
+
+	for _, table := range tables {
+		for method, root := range table.trees {
+			var params []pathParam
+
+			if root.lookup(path, &params, ignoreCase) != nil {
+				registered[method] = true
+			}
+		}
+	}
+
+	if len(registered) == 0 {
+		return nil
+	}
+
+	registered[options] = true
+	if registered[get] {
+		registered[head] = true
+	}
+
+	allowedMethods := []string{}
+	for _, method := range httpMethods {
+		if registered[method] {
+			allowedMethods = append(allowedMethods, method)
+		}
+	}
+
+	return allowedMethods
+}
+
+// Utility function to normalize a request path or route pattern into the form stored in the tree.
+// The path is cleaned and percent-decoded (see cleanPath), except "%" and "/" which are kept encoded
+// so segment boundaries don't change.
+// Paths that are already clean are returned as is, without allocating.
+func normalizePath(rawPath string) (string, error) {
+	if isCleanPath(rawPath) {
+		return rawPath, nil
+	}
+
+	segments, trailingSlash, err := cleanPath(rawPath)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteString("/" + escapeSegment(segment))
+	}
+
+	if b.Len() == 0 || trailingSlash {
+		b.WriteString("/")
+	}
+
+	return b.String(), nil
+}
+
+// isCleanPath checks that the path has no empty, "." or ".." segments and nothing to decode.
+func isCleanPath(path string) bool {
+	if path == "" || path[0] != '/' {
+		return false
+	}
+
+	for idx := 0; idx < len(path); idx++ {
+		if path[idx] == '%' {
+			return false
+		}
+
+		if path[idx] != '/' || idx == 0 {
+			continue
+		}
+
+		// Check the segment before the slash and the one after it if it's the last.
+		if path[idx-1] == '/' || isDotSegment(path, idx) {
+			return false
+		}
+	}
+
+	return !isDotSegment(path, len(path))
+}
+
+// isDotSegment checks if the segment ending at end is "." or "..".
+func isDotSegment(path string, end int) bool {
+	start := strings.LastIndexByte(path[:end], '/') + 1
+	segment := path[start:end]
+
+	return segment == "." || segment == ".."
+}
+
+var (
+	segmentEscaper   = strings.NewReplacer("%", "%25", "/", "%2F")
+	segmentUnescaper = strings.NewReplacer("%25", "%", "%2F", "/")
+)
+
+func escapeSegment(segment string) string {
+	if !strings.ContainsAny(segment, "%/") {
+		return segment
+	}
+	return segmentEscaper.Replace(segment)
+}
+
+func unescapeSegment(segment string) string {
+	if !strings.Contains(segment, "%") {
+		return segment
+	}
+	return segmentUnescaper.Replace(segment)
+}
+
+// Utility function to build the path params store from the parameters captured by the tree.
+func newPathParams(params []pathParam) *Params {
+	pathParams := NewParams()
+
+	for _, param := range params {
+		pathParams.Set(param.key, unescapeSegment(param.value))
+	}
+
+	return pathParams
+}
+
+// fillPattern builds an escaped URL path from route pattern tokens, replacing parameters and wildcards
+// with the given values in the order they appear.
+func fillPattern(tokens []patternToken, params []pathParam) string {
+	var b strings.Builder
+
+	for _, token := range tokens {
+		if token.kind == staticToken {
+			b.WriteString(escapePath(unescapeSegment(token.text)))
+			continue
+		}
+
+		if len(params) > 0 {
+			b.WriteString(escapePath(params[0].value))
+			params = params[1:]
+		}
+	}
+
+	return b.String()
+}
+
+// escapePath escapes each segment of a normalized path on its own, keeping the slashes between them.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for idx, segment := range segments {
+		segments[idx] = url.PathEscape(unescapeSegment(segment))
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,229 @@
+package goserve
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Fuad28/GOServe.git/goserve/utils"
+)
+
+// Routes are changed while requests are handled, run with -race to catch unsynchronized accesses.
+func TestRouterChangesDuringRequests(t *testing.T) {
+	server := NewServer(Config{})
+	route, _ := server.GET("/tasks/:id", func(req *Request, res IResponse) IResponse { return res.Send("task") })
+
+	const iterations = 50
+	var wg sync.WaitGroup
+
+	for worker := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for idx := range iterations {
+				raw := fmt.Sprintf("GET /tasks/%v HTTP/1.1\r\nHost: localhost\r\n\r\n", idx)
+				if worker%2 == 1 {
+					raw = fmt.Sprintf("GET /dynamic/%v HTTP/1.1\r\nHost: localhost\r\n\r\n", idx%5)
+				}
+
+				req, err := NewRequest(raw, nil, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				res := server.HandleRequest(req)
+				if code := res.StatusCode(); code != 0 && code != 200 && code != 404 && code != 405 {
+					t.Errorf("got status %v for %v", code, raw)
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for idx := range iterations {
+			path := fmt.Sprintf("/dynamic/%v", idx%5)
+
+			server.GET(path, func(req *Request, res IResponse) IResponse { return res.Send("dynamic") })
+			server.DisableRoute(get, path)
+			server.EnableRoute(get, path)
+			server.RemoveRoute(get, path)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for idx := range iterations {
+			server.AddMiddleWares(func(req *Request, res IResponse) IResponse { return req.Next(res) })
+			route.Name(fmt.Sprintf("task-%v", idx))
+			server.DisableRoute(get, "/tasks/:id")
+			server.EnableRoute(get, "/tasks/:id")
+		}
+	}()
+
+	wg.Wait()
+
+	res := sendRequest(t, server, "GET /tasks/1 HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	if res.Body() != "task" {
+		t.Errorf("got body %v, want the task route", res.Body())
+	}
+	if url, err := server.URL(fmt.Sprintf("task-%v", iterations-1), map[string]string{"id": "1"}); err != nil || url != "/tasks/1" {
+		t.Errorf("got URL %q and error %v, want /tasks/1", url, err)
+	}
+}
+
+// benchmarkRoutes returns 800 patterns: 200 resources with a collection, a detail and two nested routes each.
+func benchmarkRoutes() []string {
+	patterns := []string{}
+	for idx := range 200 {
+		patterns = append(patterns,
+			fmt.Sprintf("/resource%v", idx),
+			fmt.Sprintf("/resource%v/:id", idx),
+			fmt.Sprintf("/resource%v/:id/items", idx),
+			fmt.Sprintf("/resource%v/:id/items/:item", idx),
+		)
+	}
+	return patterns
+}
+
+func benchmarkServer(b testing.TB) *Server {
+	server := NewServer(Config{})
+	for _, pattern := range benchmarkRoutes() {
+		if _, err := server.GET(pattern, func(req *Request, res IResponse) IResponse { return res }); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return server
+}
+
+func benchmarkRequest(b testing.TB, path string) *Request {
+	req, err := NewRequest(fmt.Sprintf("GET %v HTTP/1.1\r\nHost: localhost\r\n\r\n", path), nil, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return req
+}
+
+func BenchmarkGetRouteStatic(b *testing.B) {
+	server := benchmarkServer(b)
+	req := benchmarkRequest(b, "/resource199")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		if server.GetRoute(req) == nil {
+			b.Fatal("no route matched")
+		}
+	}
+}
+
+func BenchmarkGetRouteParams(b *testing.B) {
+	server := benchmarkServer(b)
+	req := benchmarkRequest(b, "/resource199/1/items/2")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		if server.GetRoute(req) == nil {
+			b.Fatal("no route matched")
+		}
+	}
+}
+
+func BenchmarkGetRouteLinearStatic(b *testing.B) {
+	routes := newLinearRoutes(benchmarkRoutes())
+	req := benchmarkRequest(b, "/resource199")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		if routes.match(req) == "" {
+			b.Fatal("no route matched")
+		}
+	}
+}
+
+func BenchmarkGetRouteLinearParams(b *testing.B) {
+	routes := newLinearRoutes(benchmarkRoutes())
+	req := benchmarkRequest(b, "/resource199/1/items/2")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		if routes.match(req) == "" {
+			b.Fatal("no route matched")
+		}
+	}
+}
+
+// Matching a static path with the default, strict, policies mustn't allocate.
+// Paths with parameters do: the captured values are copied into the request's path params.
+func TestGetRouteStaticAllocations(t *testing.T) {
+	server := benchmarkServer(t)
+	req := benchmarkRequest(t, "/resource199")
+
+	allocations := testing.AllocsPerRun(100, func() { server.GetRoute(req) })
+	if allocations != 0 {
+		t.Errorf("got %v allocations, want 0", allocations)
+	}
+}
+
+// linearRoutes is the matcher the radix tree replaced, kept to compare them: routes are scanned in order,
+// comparing the segments of the path with those of each pattern.
+type linearRoutes struct {
+	patterns [][]string
+	paths    []string
+}
+
+func newLinearRoutes(patterns []string) *linearRoutes {
+	routes := &linearRoutes{}
+	for _, pattern := range patterns {
+		segments, _, _ := cleanPath(pattern)
+		routes.patterns = append(routes.patterns, segments)
+		routes.paths = append(routes.paths, pattern)
+	}
+	return routes
+}
+
+// match returns the pattern matching the request path, or "" if none does.
+func (routes *linearRoutes) match(req *Request) string {
+	rawPath, _, _ := strings.Cut(req.path, "?")
+	segments, _, err := cleanPath(rawPath)
+	if err != nil {
+		return ""
+	}
+
+	for idx, pattern := range routes.patterns {
+		if matched, _ := matchLinearRoute(segments, pattern); matched {
+			return routes.paths[idx]
+		}
+	}
+	return ""
+}
+
+func matchLinearRoute(path []string, route []string) (bool, *utils.KeyValueStore[string, string]) {
+	pathParams := utils.NewKeyValueStore[string, string]()
+
+	if len(path) != len(route) {
+		return false, pathParams
+	}
+
+	for idx, curPathPart := range path {
+		curRoutePart := route[idx]
+
+		if strings.HasPrefix(curRoutePart, ":") {
+			pathParams.Set(curRoutePart[1:], curPathPart)
+		} else if curRoutePart != curPathPart {
+			return false, pathParams
+		}
+	}
+
+	return true, pathParams
+}
@@ -1,28 +1,185 @@
 package goserve
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
 // Route is a representation of an HTTP route, it holds necessary information to handle  requests
 type Route struct {
 	path        string
 	handler     HandlerFunc
 	method      string
 	middleWares []HandlerFunc
+
+	// The server middlewares, route middlewares and handler, compiled when the route is registered
+	// and rebuilt when server middlewares are added, so requests don't build it.
+	chain []HandlerFunc
+
+	// Host pattern the route is bound to via server.Host(), empty if it's matched for any host.
+	host string
+
+	// CORS policy replacing the one of the CORS middleware for the route's requests, set via CORS()
+	cors *corsPolicy
+
+	// Name used to build URLs to the route with server.URL(), set via Name()
+	name string
+
+	// Matchers telling the route apart from routes registered for the same method and path, see matchers.go.
+	headerMatchers []valueMatcher
+	queryMatchers  []valueMatcher
+	consumes       []string
+	produces       []string
+
+	// Details documenting the route in the OpenAPI document, see openapi.go.
+	summary     string
+	description string
+	tags        []string
+	requestType reflect.Type
+	responses   []routeResponse
+	security    []string
+
+	// Where the route was registered, see Info()
+	file string
+	line int
+
+	// Disabled routes stay registered but aren't matched, see server.DisableRoute()
+	disabled bool
+
+	// The lock of the server the route is registered on, held while the route is changed.
+	// It's nil until the route is registered.
+	mu *sync.RWMutex
+
+	// The normalized path the route is stored under in the router, see normalizePath.
+	pattern string
+
+	// The pattern split into static text and parameters, set when the route is added to the server.
+	tokens []patternToken
 }
 
 func NewRoute(path string, method string, handler HandlerFunc, middlewares []HandlerFunc) *Route {
-	return &Route{
-		path:        path,
+	file, line := registrationSource()
+
+	route := &Route{
 		method:      method,
 		handler:     handler,
 		middleWares: middlewares,
+		file:        file,
+		line:        line,
+	}
+	route.setPath(path)
+
+	return route
+}
+
+// setPath sets the path of a route that isn't registered yet, and the normalized pattern it's stored under.
+func (route *Route) setPath(path string) {
+	pattern, err := normalizePath(path)
+	if err != nil {
+		pattern = path
 	}
+
+	route.path = path
+	route.pattern = pattern
+}
+
+// Name names the route so URLs to it can be built with server.URL(), e.g
+//
+//	route, _ := server.GET("/tasks/:id", taskDetails)
+//	route.Name("task-detail")
+func (route *Route) Name(name string) *Route {
+	defer route.lock()()
+
+	route.name = name
+	return route
 }
 
-// Handles browsers preflight requests using the CORSMiddleware as an handler
-func DefaultOptionsRoute(allowedOrigins []string) *Route {
+// lock locks the route for changes, as it may be matched concurrently once registered.
+// It returns the function unlocking it.
+func (route *Route) lock() func() {
+	if route.mu == nil {
+		return func() {}
+	}
+
+	route.mu.Lock()
+	return route.mu.Unlock
+}
+
+// ErrRouteConflict is wrapped by the errors returned when a route is a duplicate of, or ambiguous with, a registered route.
+var ErrRouteConflict = errors.New("route conflict")
+
+// conflictsWith checks if the route can't be told apart from other, a route registered before it for the same method.
+// Routes are duplicates when their patterns and matchers are the same, and ambiguous when they only differ by parameter names
+// e.g /tasks/:id and /tasks/:taskId, the second one would never be matched.
+func (route *Route) conflictsWith(other *Route) error {
+	if route.method != other.method || route.host != other.host || routeShape(route.tokens) != routeShape(other.tokens) {
+		return nil
+	}
+
+	// Routes for the same path are told apart by their matchers, which are set once the route is registered.
+	// So a new route only conflicts with a route without matchers, the fallback has to be registered last.
+	// Routes given the same matchers afterwards are caught by server.Validate().
+	if route.matcherSignature() != other.matcherSignature() {
+		return nil
+	}
+
+	if route.pattern == other.pattern {
+		return fmt.Errorf("%w: %v %v is already registered", ErrRouteConflict, route.method, route.host+route.path)
+	}
+
+	return fmt.Errorf(
+		"%w: %v %v is ambiguous with %v %v, parameters at the same position must have the same name",
+		ErrRouteConflict, route.method, route.host+route.path, other.method, other.host+other.path,
+	)
+}
+
+// routeShape describes the paths matched by the pattern tokens, leaving out parameter names.
+func routeShape(tokens []patternToken) string {
+	var b strings.Builder
+
+	for _, token := range tokens {
+		switch token.kind {
+		case staticToken:
+			b.WriteString(token.text)
+		case paramToken:
+			b.WriteString(":<" + token.constraint + ">")
+		case wildcardToken:
+			b.WriteString("*")
+		case catchAllToken:
+			b.WriteString("**")
+		}
+	}
+
+	return b.String()
+}
+
+// Handles OPTIONS requests on paths without an explicit OPTIONS route.
+// The Allow header lists allowedMethods, the methods registered for the path.
+// Browsers preflight requests are handled as done by CORSMiddlewareWithConfig for allowedOrigins,
+// with the methods allowed in preflight requests set to allowedMethods as well.
+func DefaultOptionsRoute(allowedOrigins []string, allowedMethods []string) *Route {
+	policy := newCORSPolicy(CORSConfig{AllowedOrigins: allowedOrigins, AllowedMethods: allowedMethods})
+	allow := strings.Join(allowedMethods, ", ")
+
+	handler := func(req *Request, res IResponse) IResponse {
+		res.SetHeader("Allow", allow)
+
+		if _, isPreflight := preflightMethod(req); !isPreflight || isSameOrigin(req) {
+			return res.SetStatus(status.HTTP_204_NO_CONTENT).Send(nil)
+		}
+
+		return policy.forRoute(req).handle(req, res)
+	}
+
 	return &Route{
 		path:        "*",
 		method:      options,
-		handler:     CORSMiddleware(allowedOrigins),
+		handler:     handler,
 		middleWares: []HandlerFunc{},
 	}
 }
@@ -2,15 +2,17 @@ package goserve
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"runtime/debug"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/Fuad28/GOServe.git/goserve/status"
-	"github.com/Fuad28/GOServe.git/goserve/utils"
 )
 
 // The server holds it all together and provides methods that handle routes and requests
@@ -19,8 +21,17 @@ type Server struct {
 
 	// Holds all the registered routes
 	// The server is the root route.
-	// Accessed via routes()
-	routes []Route
+	// Accessed via Routes()
+	routes []*Route
+
+	// Radix trees of the registered routes per method, used to match requests.
+	trees map[string]*node
+
+	// Routes bound to a host via Host(), exact hosts first.
+	hosts []*hostRoutes
+
+	// Guards routes, trees, hosts and middleWares, as routes can be added and removed while requests are handled.
+	routesMu sync.RWMutex
 
 	// config holds important user-set details for the servers to start.
 	// Defaults are set where not provided.
@@ -35,13 +46,61 @@ type Server struct {
 
 	// This is the TCP Address of the server
 	addr *net.TCPAddr
+
+	// Networks of the proxies whose forwarding headers are trusted when resolving the client IP, scheme and host.
+	// Set via Config.TrustedProxies or AddTrustedProxies()
+	trustedProxies []*net.IPNet
+
+	// Messages used for validation errors, keyed by language then rule.
+	// Set via AddValidationMessages()
+	validationMessages map[string]map[string]string
+
+	// Custom validators usable in `valid` tags, keyed by name.
+	// Set via AddValidator()
+	validators map[string]ValidatorFunc
+
+	// The listener accepting connections, closed by Shutdown()
+	listener   net.Listener
+	listenerMu sync.Mutex
+
+	// Handlers for requests not matching any route, matching a path but not its methods and failing to parse.
+	// Set via NotFound(), MethodNotAllowed(), OnParseError() and OnError(), defaults are used when nil.
+	notFoundHandler         HandlerFunc
+	methodNotAllowedHandler HandlerFunc
+	parseErrorHandler       ParseErrorHandlerFunc
+	errorHandler            ErrorResponderFunc
+
+	// Reports panics recovered while handling requests, set via OnPanic()
+	panicHandler PanicHandlerFunc
+
+	// Logs the events of the server, set via Config.Logger
+	// Accessed via Logger()
+	logger *slog.Logger
+
+	// Errors mapped to responses by the default error handler, set via MapError()
+	errorMappings []errorMapping
+
+	// ctx is the parent of all request contexts, cancel is called on Shutdown() to cancel them.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func (s *Server) Routes() []Route {
-	return s.routes
+	s.routesMu.RLock()
+	defer s.routesMu.RUnlock()
+
+	routes := make([]Route, len(s.routes))
+	for idx, route := range s.routes {
+		routes[idx] = *route
+	}
+
+	return routes
 }
 
 func (s *Server) MiddleWares() []HandlerFunc {
+	s.routesMu.RLock()
+	defer s.routesMu.RUnlock()
+
 	return s.middleWares
 }
 
@@ -50,6 +109,7 @@ func (s *Server) AllowedOrigins() []string {
 }
 
 // Creates a new server based on config set and returns a pointer to the server instance.
+// It panics if Config.TrustedProxies holds an invalid CIDR or IP.
 func NewServer(config Config) *Server {
 	if config.Port == 0 {
 		config.Port = 8000
@@ -58,9 +118,22 @@ func NewServer(config Config) *Server {
 		config.MaxRequestSize = ONE_MB
 	}
 
-	return &Server{
-		config: config,
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := &Server{
+		config:         config,
+		allowedOrigins: slices.Clone(config.AllowedOrigins),
+		logger:         config.Logger,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	// An invalid proxy list is a configuration mistake that should be caught at start up.
+	if err := server.AddTrustedProxies(config.TrustedProxies); err != nil {
+		panic(err)
 	}
+
+	return server
 }
 
 // Address is used to obtain the address of the server.
@@ -83,21 +156,206 @@ func (s *Server) Address() (*net.TCPAddr, error) {
 }
 
 // AddRoute is used to register routes on the server.
+// Paths can hold parameters (/tasks/:id), constrained parameters (/tasks/:id<int>), single segment wildcards (/files/*/meta)
+// and a catch-all parameter at the end (/files/*filepath), see pattern.go for the full syntax.
+// An error is returned if the path is invalid, or if the route is a duplicate of or ambiguous with a registered route
+// (see ErrRouteConflict). AddRoute panics instead when Config.StrictRoutes is set.
 func (s *Server) AddRoute(path string, method string, handler HandlerFunc, middlewares []HandlerFunc) (*Route, error) {
-	if slices.Contains(httpMethods, method) {
-		newRoute := NewRoute(path, method, handler, middlewares)
-		s.routes = append(s.routes, *newRoute)
+	return s.addHostRoute("", NewRoute(path, method, handler, middlewares))
+}
+
+// addHostRoute registers a route built with NewRoute() bound to the host pattern, or matched for any host if it's empty.
+func (s *Server) addHostRoute(host string, route *Route) (*Route, error) {
+	newRoute, err := s.addRoute(host, route)
+	if err != nil && s.config.StrictRoutes {
+		panic(err)
+	}
+
+	return newRoute, err
+}
 
-		return newRoute, nil
+func (s *Server) addRoute(host string, newRoute *Route) (*Route, error) {
+	method := newRoute.method
+	if !slices.Contains(httpMethods, method) {
+		return nil, errors.New("invalid method")
+	}
+
+	newRoute.host = host
+	newRoute.mu = &s.routesMu
+
+	tokens, err := tokenizePattern(newRoute.pattern)
+	if err != nil {
+		return nil, err
+	}
+	newRoute.tokens = tokens
+
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+
+	if s.trees == nil {
+		s.trees = map[string]*node{}
+	}
+
+	trees := s.trees
+	if host != "" {
+		hostRoutes, err := s.hostRoutes(host)
+		if err != nil {
+			return nil, err
+		}
+		trees = hostRoutes.trees
+	}
+
+	for _, route := range s.routes {
+		if err := newRoute.conflictsWith(route); err != nil {
+			return nil, err
+		}
+	}
+
+	newRoute.chain = s.compileChain(newRoute.middleWares, newRoute.handler)
+	s.routes = append(s.routes, newRoute)
+
+	if trees[method] == nil {
+		trees[method] = &node{}
+	}
+	trees[method].insert(newRoute.tokens, newRoute)
+
+	return newRoute, nil
+}
+
+// Validate checks the whole route table and returns all the problems found, joined together.
+// Call it before StartAndListen to catch routes without handlers, conflicting routes and names used more than once.
+func (s *Server) Validate() error {
+	s.routesMu.RLock()
+	defer s.routesMu.RUnlock()
+
+	errs := []error{}
+	names := map[string]*Route{}
+
+	for idx, route := range s.routes {
+		if route.handler == nil {
+			errs = append(errs, fmt.Errorf("%v %v has no handler", route.method, route.path))
+		}
+
+		for _, other := range s.routes[:idx] {
+			if err := route.conflictsWith(other); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if route.name == "" {
+			continue
+		}
+		if other, exists := names[route.name]; exists {
+			errs = append(errs, fmt.Errorf("route name %q is used by %v %v and %v %v", route.name, other.method, other.path, route.method, route.path))
+			continue
+		}
+		names[route.name] = route
 	}
 
-	return nil, errors.New("invalid method")
+	return errors.Join(errs...)
+}
+
+// RemoveRoute unregisters the routes registered for method and path, including those bound to hosts
+// or told apart by matchers. It's safe to call while the server is handling requests.
+// An error is returned if no route is registered for them.
+func (s *Server) RemoveRoute(method string, path string) error {
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+
+	removed := s.findRoutes(method, path)
+	if len(removed) == 0 {
+		return fmt.Errorf("no route is registered for %v %v", method, path)
+	}
+
+	for _, route := range removed {
+		if !route.disabled {
+			s.routeTrees(route)[route.method].remove(route.tokens, route)
+		}
+	}
+
+	s.routes = slices.DeleteFunc(s.routes, func(route *Route) bool { return slices.Contains(removed, route) })
+
+	return nil
+}
+
+// DisableRoute stops the routes registered for method and path from being matched, without unregistering them.
+// Requests get the response they would get if the routes weren't registered, until EnableRoute is called.
+// It's safe to call while the server is handling requests.
+func (s *Server) DisableRoute(method string, path string) error {
+	return s.toggleRoute(method, path, false)
+}
+
+// EnableRoute matches the routes disabled with DisableRoute again.
+func (s *Server) EnableRoute(method string, path string) error {
+	return s.toggleRoute(method, path, true)
+}
+
+func (s *Server) toggleRoute(method string, path string, enabled bool) error {
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+
+	routes := s.findRoutes(method, path)
+	if len(routes) == 0 {
+		return fmt.Errorf("no route is registered for %v %v", method, path)
+	}
+
+	for _, route := range routes {
+		if route.disabled != enabled {
+			continue
+		}
+		route.disabled = !enabled
+
+		trees := s.routeTrees(route)
+		if enabled {
+			trees[route.method].insert(route.tokens, route)
+		} else {
+			trees[route.method].remove(route.tokens, route)
+		}
+	}
+
+	return nil
+}
+
+// findRoutes returns the routes registered for method and path, comparing normalized paths.
+func (s *Server) findRoutes(method string, path string) []*Route {
+	pattern, err := normalizePath(path)
+	if err != nil {
+		return nil
+	}
+
+	routes := []*Route{}
+	for _, route := range s.routes {
+		if route.method == method && route.pattern == pattern {
+			routes = append(routes, route)
+		}
+	}
+
+	return routes
+}
+
+// routeTrees returns the trees the route is stored in, those of its host or the server's.
+func (s *Server) routeTrees(route *Route) map[string]*node {
+	for _, host := range s.hosts {
+		if host.pattern == route.host {
+			return host.trees
+		}
+	}
+
+	return s.trees
 }
 
 // AddMiddleWares is used to mount middlewares on the server
 // e.g server.AddMiddleWares(goserve.CORSMiddleware(route.AllowedOrigins))
 func (s *Server) AddMiddleWares(middleware ...HandlerFunc) {
-	s.middleWares = append(s.middleWares, middleware...)
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+
+	// A new slice is built, so chains being run by requests are never changed.
+	s.middleWares = slices.Concat(s.middleWares, middleware)
+
+	for _, route := range s.routes {
+		route.chain = s.compileChain(route.middleWares, route.handler)
+	}
 }
 
 // AddAllowedOrigins is used to add new trusted origins for CORS after the server has been initialized.
@@ -105,63 +363,325 @@ func (s *Server) AddAllowedOrigins(addresses []string) {
 	s.allowedOrigins = append(s.allowedOrigins, addresses...)
 }
 
+// NotFound sets the handler for requests that don't match any route, replacing the default 404 "Path not found." response.
+// It runs after the server middlewares, so logging and CORS still apply.
+func (s *Server) NotFound(handler HandlerFunc) {
+	s.notFoundHandler = handler
+}
+
+// MethodNotAllowed sets the handler for requests whose path is registered for other methods only,
+// replacing the default 405 "Method not allowed." response.
+// The Allow header is set on the response before the handler is called.
+// It runs after the server middlewares, so logging and CORS still apply.
+func (s *Server) MethodNotAllowed(handler HandlerFunc) {
+	s.methodNotAllowedHandler = handler
+}
+
+// OnParseError sets the handler for requests that couldn't be read or parsed,
+// replacing the default 400 {"error": ...} response.
+// It runs after the server middlewares, with a request only holding the client and server addresses.
+func (s *Server) OnParseError(handler ParseErrorHandlerFunc) {
+	s.parseErrorHandler = handler
+}
+
 // GetRoute matches requests path and method with registered routes.
 // All GET routes also handle HEAD request even when not explictly set.
 // All routes handle OPTIONS requests even when not explictly set.
 // When handling OPTIONS request when not explictyly set, the route.DefaultOptionsRoute handler is used.
+// The request path is cleaned and percent-decoded before matching, see cleanPath.
+// Trailing slash and case differences are matched according to Config.TrailingSlash and Config.CaseSensitivity,
+// GetRoute doesn't redirect so routes that would be redirected to are returned as matches.
 
 func (s *Server) GetRoute(req *Request) *Route {
-	pathParts := strings.SplitN(req.path, "?", 2)
+	return s.findRoute(req).route
+}
 
-	for _, route := range s.routes {
-		isPathMatch, pathParams := matchRoute(pathParts[0], route.path)
-		isMethodMatch := req.method == route.method
+// routeMatch is the result of matching a request with the registered routes.
+type routeMatch struct {
+	route *Route
+
+	// The canonical path to redirect to, set when the matched route only differs in its trailing slash or case
+	// and the policy is PathRedirect.
+	redirectTo string
+
+	// The methods registered for the path, set when the path matched but the method didn't.
+	allowedMethods []string
+
+	// Set to 406 or 415 when routes are registered for the path and method, but their matchers don't fit the request.
+	status int
+}
+
+// findRoute matches the request with the registered routes.
+func (s *Server) findRoute(req *Request) routeMatch {
+	s.routesMu.RLock()
+	defer s.routesMu.RUnlock()
 
-		// For a HEAD request, check that the route's method is HEAD or GET.
-		if req.method == head {
-			isMethodMatch = (route.method == get) || (route.method == head)
+	rawPath, rawQuery, hasQuery := strings.Cut(req.path, "?")
+	req.pathParams = nil
+	req.mediaType = ""
+
+	path, err := normalizePath(rawPath)
+	if err != nil {
+		return routeMatch{}
+	}
+
+	// The exact path is looked up first, then the relaxed ones allowed by the policies.
+	// A relaxed candidate only matches when the ones before it didn't, so its differences are known.
+	// The candidates and route tables are built in arrays on the stack, so matching doesn't allocate
+	// unless parameters are captured or a relaxed path has to be built.
+	type candidate struct {
+		path       string
+		ignoreCase bool
+	}
+
+	var pathsArray [2]string
+	paths := append(pathsArray[:0], path)
+	if s.config.TrailingSlash != PathStrict && path != "/" {
+		if strings.HasSuffix(path, "/") {
+			paths = append(paths, strings.TrimSuffix(path, "/"))
+		} else {
+			paths = append(paths, path+"/")
+		}
+	}
+
+	var candidatesArray [4]candidate
+	candidates := candidatesArray[:0]
+	for _, candidatePath := range paths {
+		candidates = append(candidates, candidate{candidatePath, false})
+
+		if s.config.CaseSensitivity != PathStrict {
+			candidates = append(candidates, candidate{candidatePath, true})
+		}
+	}
+
+	var tablesArray [4]routeTable
+	tables := s.routeTables(req, tablesArray[:0])
+
+	// Set when routes are registered for the path and method but their header or query matchers don't fit the request.
+	matchersMissed := false
+
+	for idx, candidate := range candidates {
+		route, params, hostParams, statusCode := s.matchMethod(req, tables, candidate.path, candidate.ignoreCase)
+		if statusCode == status.HTTP_406_NOT_ACCEPTABLE || statusCode == status.HTTP_415_UNSUPPORTED_MEDIA_TYPE {
+			return routeMatch{status: statusCode}
+		}
+		if statusCode == status.HTTP_404_NOT_FOUND {
+			matchersMissed = true
+		}
+		if route == nil {
+			continue
 		}
 
-		// For an OPTIONS request, if the matched route isn't an OPTIONS route, return custom route
-		if (isPathMatch) && (req.method == options) && (route.method != options) {
-			return DefaultOptionsRoute(s.allowedOrigins)
+		if len(params) > 0 || len(hostParams) > 0 {
+			req.pathParams = newPathParams(slices.Concat(hostParams, params))
 		}
 
-		if isPathMatch && isMethodMatch {
-			req.pathParams = pathParams
+		if idx == 0 || route.path == "*" {
+			return routeMatch{route: route}
+		}
 
-			if len(pathParts) > 1 {
-				req.queryParams = parseQueryParams(pathParts[1])
-			} else {
-				req.queryParams = utils.NewKeyValueStore[string, string]()
+		trailingSlashDiffers := candidate.path != path
+		caseDiffers := candidate.ignoreCase
+
+		if (trailingSlashDiffers && s.config.TrailingSlash == PathRedirect) || (caseDiffers && s.config.CaseSensitivity == PathRedirect) {
+			canonicalPath := fillPattern(route.tokens, params)
+			if hasQuery {
+				canonicalPath += "?" + rawQuery
 			}
+			return routeMatch{route: route, redirectTo: canonicalPath}
+		}
+
+		return routeMatch{route: route}
+	}
+
+	// The method is registered for the path, so the request mustn't get a 405 listing it in the Allow header.
+	if matchersMissed {
+		return routeMatch{status: status.HTTP_404_NOT_FOUND}
+	}
 
-			return &route
+	// No route matched the method, check if the path is registered for other methods.
+	for _, candidate := range candidates {
+		if allowedMethods := allowedMethods(tables, candidate.path, candidate.ignoreCase); len(allowedMethods) > 0 {
+			return routeMatch{allowedMethods: allowedMethods}
 		}
 	}
 
-	return nil
+	return routeMatch{}
+}
+
+// matchMethod looks up the route for the request method and the normalized path in the route tables, in order.
+// It returns the route with the parameters captured from the path and the host.
+// When routes are registered for the path but their matchers don't fit the request, the next tables are tried,
+// e.g a host route whose matchers don't fit falls through to the routes of any host.
+// If none fits, the status of the first miss is returned: 404, 406 or 415 (see selectRoute).
+// OPTIONS requests on paths without an OPTIONS route are handled by DefaultOptionsRoute.
+func (s *Server) matchMethod(req *Request, tables []routeTable, path string, ignoreCase bool) (*Route, []pathParam, []pathParam, int) {
+	missedStatus := 0
+
+	for _, table := range tables {
+		routes, params := lookupRoutes(table.trees, req.method, path, ignoreCase)
+		if routes == nil {
+			continue
+		}
+
+		route, statusCode := selectRoute(req, routes)
+		if route != nil {
+			return route, params, table.hostParams, 0
+		}
+
+		if missedStatus == 0 {
+			missedStatus = statusCode
+		}
+	}
+
+	if missedStatus != 0 {
+		return nil, nil, nil, missedStatus
+	}
+
+	if req.method == options {
+		if allowedMethods := allowedMethods(tables, path, ignoreCase); len(allowedMethods) > 0 {
+			return DefaultOptionsRoute(s.allowedOrigins, allowedMethods), nil, nil, 0
+		}
+	}
+
+	return nil, nil, nil, 0
 }
 
 // HandleRequest processes the requests:
 // 1. it initializes the response
 // 2. create the HandlerChain and passes the requests into it
 // 3. matches registered routes and requests
-// 4. handles not found routes
+// 4. handles not found routes, methods not allowed and redirects to canonical paths
 // 5. returns the final response
+// Panics of the handlers and middlewares are recovered and answered with a 500 response, see OnPanic()
 
-func (s *Server) HandleRequest(req *Request) IResponse {
+func (s *Server) HandleRequest(req *Request) (response IResponse) {
+	defer s.recoverPanic(req, &response)
+
+	req.server = s
 	res := NewResponse(req)
-	route := s.GetRoute(req)
+	match := s.findRoute(req)
+	route := match.route
+
+	if route == nil && len(match.allowedMethods) > 0 {
+		res.SetHeader("Allow", strings.Join(match.allowedMethods, ", "))
+
+		handler := s.methodNotAllowedHandler
+		if handler == nil {
+			handler = defaultMethodNotAllowedHandler
+		}
+
+		return s.runHandlerChain(req, res, nil, handler)
+	}
+
+	if route == nil && match.status == status.HTTP_406_NOT_ACCEPTABLE {
+		return s.runHandlerChain(req, res, nil, defaultNotAcceptableHandler)
+	}
+
+	if route == nil && match.status == status.HTTP_415_UNSUPPORTED_MEDIA_TYPE {
+		return s.runHandlerChain(req, res, nil, defaultUnsupportedMediaTypeHandler)
+	}
 
 	if route == nil {
-		return res.SetStatus(status.HTTP_404_NOT_FOUND).Send("Path not found.")
+		handler := s.notFoundHandler
+		if handler == nil {
+			handler = defaultNotFoundHandler
+		}
+
+		return s.runHandlerChain(req, res, nil, handler)
+	}
+
+	// Redirects go through the server middlewares as other responses do, e.g they are access logged.
+	if match.redirectTo != "" {
+		return s.runHandlerChain(req, res, nil, canonicalRedirectHandler(match.redirectTo))
+	}
+
+	req.route = route
+
+	// Routes declaring the types they produce respond with the negotiated one, unless their handler sets another.
+	if req.mediaType != "" && !strings.Contains(req.mediaType, "*") {
+		res.SetHeader("Content-Type", req.mediaType)
 	}
 
-	handlerChain := append(append(s.middleWares, route.middleWares...), route.handler)
-	req.handlerChain = utils.NewQueue[HandlerFunc](handlerChain)
+	s.routesMu.RLock()
+	handlers := route.chain
+	s.routesMu.RUnlock()
 
-	return req.Next(res)
+	// Routes that aren't registered e.g the default OPTIONS route don't have a compiled chain.
+	if handlers == nil {
+		return s.runHandlerChain(req, res, route.middleWares, route.handler)
+	}
+
+	return req.runChain(handlers, res)
+}
+
+// runHandlerChain passes the request through the server middlewares, then the given middlewares and the handler.
+func (s *Server) runHandlerChain(req *Request, res IResponse, middlewares []HandlerFunc, handler HandlerFunc) IResponse {
+	s.routesMu.RLock()
+	handlers := s.compileChain(middlewares, handler)
+	s.routesMu.RUnlock()
+
+	return req.runChain(handlers, res)
+}
+
+// compileChain returns the server middlewares, the panic recovery step, then the given middlewares and the handler in a new slice.
+// The caller must hold routesMu.
+func (s *Server) compileChain(middlewares []HandlerFunc, handler HandlerFunc) []HandlerFunc {
+	return slices.Concat(s.middleWares, []HandlerFunc{s.recoverMiddleware}, middlewares, []HandlerFunc{handler})
+}
+
+// handleParseError responds to a request that couldn't be read or parsed using the parse error handler.
+func (s *Server) handleParseError(clientAddr *net.TCPAddr, err error) (response IResponse) {
+	req := s.newBareRequest(clientAddr)
+	defer s.recoverPanic(req, &response)
+
+	res := NewResponse(nil)
+
+	parseErrorHandler := s.parseErrorHandler
+	if parseErrorHandler == nil {
+		parseErrorHandler = defaultParseErrorHandler
+	}
+
+	handler := func(req *Request, res IResponse) IResponse {
+		return parseErrorHandler(req, res, err)
+	}
+
+	return s.runHandlerChain(req, res, nil, handler)
+}
+
+// canonicalRedirectHandler redirects to the canonical path of the route matched with a relaxed policy.
+// 301 may be followed with a GET by clients, so 308 is used to preserve the method and body of other requests.
+func canonicalRedirectHandler(location string) HandlerFunc {
+	return func(req *Request, res IResponse) IResponse {
+		redirectStatus := status.HTTP_308_PERMANENT_REDIRECT
+		if req.method == get || req.method == head {
+			redirectStatus = status.HTTP_301_MOVED_PERMANENTLY
+		}
+
+		return res.SetStatus(redirectStatus).SetHeader("Location", location).Send(nil)
+	}
+}
+
+// Default handlers used when NotFound(), MethodNotAllowed() or OnParseError() aren't set.
+func defaultNotFoundHandler(req *Request, res IResponse) IResponse {
+	return res.SetStatus(status.HTTP_404_NOT_FOUND).Send("Path not found.")
+}
+
+func defaultMethodNotAllowedHandler(req *Request, res IResponse) IResponse {
+	return res.SetStatus(status.HTTP_405_METHOD_NOT_ALLOWED).Send("Method not allowed.")
+}
+
+func defaultParseErrorHandler(req *Request, res IResponse, err error) IResponse {
+	return res.SetStatus(status.HTTP_400_BAD_REQUEST).Send(JSON{"error": err.Error()})
+}
+
+// Handlers for requests whose route matchers don't fit, see matchers.go.
+func defaultNotAcceptableHandler(req *Request, res IResponse) IResponse {
+	return res.SetStatus(status.HTTP_406_NOT_ACCEPTABLE).Send("Not acceptable.")
+}
+
+func defaultUnsupportedMediaTypeHandler(req *Request, res IResponse) IResponse {
+	return res.SetStatus(status.HTTP_415_UNSUPPORTED_MEDIA_TYPE).Send("Unsupported media type.")
 }
 
 // GET is shortcut for s.AddRoute(path, get, handler, middlewares)
@@ -203,61 +723,110 @@ func (s *Server) HEAD(path string, handler HandlerFunc, middlewares ...HandlerFu
 // StartAndListen is a blocking code that waits for new connections, processes them (asynchronously) and sends responses when done.
 // Handles errors that may arise during server start up.
 // Handles closing of connections and listner.
-func (s *Server) StartAndListen() {
+// It returns nil once Shutdown is called, or an error if the port can't be bound or connections can't be accepted.
+func (s *Server) StartAndListen() error {
 	port := s.config.Port
 	l, err := net.Listen("tcp", fmt.Sprint(":", port))
-	defer l.Close()
 
 	if err != nil {
-		log.Fatalf("Failed to bind to port %v: %v", port, err.Error())
+		return fmt.Errorf("failed to bind to port %v: %w", port, err)
 	}
+	defer l.Close()
 
-	log.Println("Server running on port ", port)
+	s.listenerMu.Lock()
+	s.listener = l
+	s.listenerMu.Unlock()
+
+	s.Logger().Info("server running", "port", port)
 
 	for {
 		conn, err := l.Accept()
-		defer conn.Close()
 
 		if err != nil {
-			log.Fatalf("Error accepting connection: %v", err.Error())
+			// Accept fails once the listener is closed by Shutdown
+			if s.ctx.Err() != nil {
+				s.Logger().Info("server stopped", "port", port)
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
 		}
 
-		clientAddr := conn.RemoteAddr().(*net.TCPAddr)
-		serverAddr := s.addr
+		go s.handleConnection(conn)
+	}
+}
 
-		go func() {
-			response := NewResponse(nil)
-			request := make([]byte, s.config.MaxRequestSize)
-			_, err = conn.Read(request)
-			request = bytes.Trim(request, "\x00")
+// Shutdown stops the server from accepting new connections and cancels the context of all in-flight requests.
+func (s *Server) Shutdown() {
+	s.cancel()
 
-			if err != nil {
-				errStr := fmt.Sprint("Error reading request: ", err.Error())
-				response.SetStatus(status.HTTP_400_BAD_REQUEST).Send(JSON{"error": errStr})
-				conn.Write(response.GetResponseByte(false))
-				conn.Close()
+	s.listenerMu.Lock()
+	defer s.listenerMu.Unlock()
 
-				return
-			}
+	if s.listener != nil {
+		s.listener.Close()
+	}
+}
 
-			req, err := NewRequest(string(request), clientAddr, serverAddr)
-			if err != nil {
-				errStr := fmt.Sprint("Error creating request instance: ", err.Error())
-				response.SetStatus(status.HTTP_400_BAD_REQUEST).Send(JSON{"error": errStr})
-				conn.Write(response.GetResponseByte(false))
-				conn.Close()
+// handleConnection reads the request from the connection, handles it and writes the response back.
+// The request context is cancelled when the client disconnects, the server shuts down or Config.HandlerTimeout elapses.
+func (s *Server) handleConnection(conn net.Conn) {
+	defer conn.Close()
 
-				return
-			}
+	clientAddr := conn.RemoteAddr().(*net.TCPAddr)
+	serverAddr := s.addr
 
-			res := s.HandleRequest(req)
-			isHead := req.method == head
-			conn.Write(res.GetResponseByte(isHead))
+	// Requests are handled with their panics recovered, this catches those happening while reading and parsing them.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			res := s.handlePanic(s.newBareRequest(clientAddr), recovered, debug.Stack())
+			conn.Write(res.GetResponseByte(false))
+		}
+	}()
+
+	request := make([]byte, s.config.MaxRequestSize)
+	_, err := conn.Read(request)
+	request = bytes.Trim(request, "\x00")
+
+	if err != nil {
+		s.Logger().Debug("failed to read request", "client", clientAddr.String(), "error", err)
+		res := s.handleParseError(clientAddr, fmt.Errorf("Error reading request: %w", err))
+		conn.Write(res.GetResponseByte(false))
+
+		return
+	}
+
+	req, err := NewRequest(string(request), clientAddr, serverAddr)
+	if err != nil {
+		s.Logger().Warn("invalid request", "client", clientAddr.String(), "error", err)
+		res := s.handleParseError(clientAddr, fmt.Errorf("Error creating request instance: %w", err))
+		conn.Write(res.GetResponseByte(false))
+
+		return
+	}
 
-			// Log Request & Response
-			log.Printf("%v %v %v %v\n", req.method, req.path, req.httpVersion, res.StatusCode())
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
 
-			conn.Close()
-		}()
+	if s.config.HandlerTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.config.HandlerTimeout)
+		defer cancel()
 	}
+	req.ctx = ctx
+
+	// Reads only fail once the client hangs up or the connection is closed, so that's when the context is cancelled.
+	// Data sent after the part of the request that was read (the rest of a large body, a pipelined request) is discarded,
+	// as the connection is closed once the response is written.
+	go func() {
+		discarded := make([]byte, 512)
+		for {
+			if _, err := conn.Read(discarded); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	res := s.HandleRequest(req)
+	isHead := req.method == head
+	conn.Write(res.GetResponseByte(isHead))
 }
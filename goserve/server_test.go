@@ -0,0 +1,107 @@
+package goserve
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveConnection accepts a single connection on a local listener and handles it, returning the client side.
+func serveConnection(t *testing.T, server *Server) net.Conn {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			server.handleConnection(conn)
+		}
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestDataAfterRequestDoesNotCancelContext(t *testing.T) {
+	server := NewServer(Config{})
+	server.POST("/upload", func(req *Request, res IResponse) IResponse {
+		time.Sleep(100 * time.Millisecond)
+
+		if req.Context().Err() != nil {
+			return res.Send("cancelled")
+		}
+		return res.Send("alive")
+	})
+
+	conn := serveConnection(t, server)
+	conn.Write([]byte("POST /upload HTTP/1.1\r\nHost: localhost\r\n\r\n{}"))
+
+	// More data arriving while the request is handled, e.g the rest of a body larger than the read buffer.
+	time.Sleep(20 * time.Millisecond)
+	conn.Write([]byte("more data"))
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(response), "alive") {
+		t.Errorf("got response %q, want the context to still be alive", response)
+	}
+}
+
+func TestClientDisconnectCancelsContext(t *testing.T) {
+	server := NewServer(Config{})
+	cancelled := make(chan bool, 1)
+
+	server.GET("/slow", func(req *Request, res IResponse) IResponse {
+		select {
+		case <-req.Context().Done():
+			cancelled <- true
+		case <-time.After(time.Second):
+			cancelled <- false
+		}
+		return res
+	})
+
+	conn := serveConnection(t, server)
+	conn.Write([]byte("GET /slow HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+
+	time.Sleep(20 * time.Millisecond)
+	conn.Close()
+
+	if !<-cancelled {
+		t.Error("got the context alive after the client disconnected")
+	}
+}
+
+func TestRedirectGoesThroughServerMiddlewares(t *testing.T) {
+	var accessLog bytes.Buffer
+
+	server := NewServer(Config{TrailingSlash: PathRedirect})
+	server.AddMiddleWares(AccessLogMiddleware(AccessLogConfig{Output: &accessLog}))
+	server.GET("/tasks", func(req *Request, res IResponse) IResponse { return res.Send("tasks") })
+
+	res := sendRequest(t, server, "GET /tasks/ HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	if location, _ := res.Headers().Get("Location"); res.StatusCode() != 301 || location != "/tasks" {
+		t.Errorf("got status %v and Location %q, want a 301 to /tasks", res.StatusCode(), location)
+	}
+	if requestID, _ := res.Headers().Get("X-Request-ID"); requestID == "" {
+		t.Error("got no X-Request-ID header, want the one set by the access log")
+	}
+	if line := accessLog.String(); !strings.Contains(line, `"GET /tasks/ HTTP/1.1" 301`) {
+		t.Errorf("got access log %q, want the redirect logged", line)
+	}
+}
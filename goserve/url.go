@@ -0,0 +1,79 @@
+package goserve
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL builds the path to the route named name (see route.Name()), filling its parameters from params e.g
+//
+//	server.URL("task-detail", map[string]string{"id": "42", "fields": "title"}) // /tasks/42?fields=title
+//
+// Values are escaped, so a "/" in a :param value is sent as %2F while the segments of a catch-all value are kept apart.
+// Single segment wildcards are filled from the "*" key.
+// Params that aren't in the path are added as query parameters, sorted by key.
+// An error is returned if no route has the name, a parameter is missing or a value doesn't match its constraint.
+func (s *Server) URL(name string, params map[string]string) (string, error) {
+	s.routesMu.RLock()
+	defer s.routesMu.RUnlock()
+
+	var route *Route
+	for _, registered := range s.routes {
+		if registered.name == name {
+			route = registered
+			break
+		}
+	}
+
+	if route == nil {
+		return "", fmt.Errorf("no route is named %q", name)
+	}
+
+	used := map[string]bool{}
+	pathParams := []pathParam{}
+
+	for _, token := range route.tokens {
+		if token.kind == staticToken {
+			continue
+		}
+
+		value, exists := params[token.text]
+		if !exists || (value == "" && token.kind != catchAllToken) {
+			return "", fmt.Errorf("route %q: missing value for parameter %v", name, token.text)
+		}
+
+		if token.regexp != nil && !token.regexp.MatchString(value) {
+			return "", fmt.Errorf("route %q: value %q of parameter %v doesn't match %v", name, value, token.text, token.constraint)
+		}
+
+		// Values are stored as in normalized paths, so only the slashes of catch-all values separate segments.
+		if token.kind == catchAllToken {
+			segments := strings.Split(value, "/")
+			for idx, segment := range segments {
+				segments[idx] = escapeSegment(segment)
+			}
+			value = strings.Join(segments, "/")
+		} else {
+			value = escapeSegment(value)
+		}
+
+		pathParams = append(pathParams, pathParam{key: token.text, value: value})
+		used[token.text] = true
+	}
+
+	path := fillPattern(route.tokens, pathParams)
+
+	query := url.Values{}
+	for key, value := range params {
+		if !used[key] {
+			query.Set(key, value)
+		}
+	}
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return path, nil
+}
@@ -0,0 +1,287 @@
+package goserve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	validator "github.com/asaskevich/govalidator"
+
+	"github.com/Fuad28/GOServe.git/goserve/status"
+)
+
+// Rules reported for errors that don't come from a `valid` tag.
+const (
+	// The value sent can't be converted to the type of the field.
+	ruleType = "type"
+
+	// The body isn't valid JSON.
+	ruleJSON = "json"
+)
+
+// ValidatorFunc is the signature of custom validators registered with server.AddValidator.
+// value is the field value and parent is the struct holding the field.
+type ValidatorFunc func(value any, parent any) bool
+
+// FieldError describes why a single field could not be bound or validated.
+type FieldError struct {
+	// Field is the name of the field as the client sees it i.e the tag name, nested fields are joined with dots.
+	Field string `json:"field"`
+
+	// Source is where the field is read from: path, query, header, cookie or body.
+	Source string `json:"source"`
+
+	// Rule is the name of the failed validator e.g required, email or type for conversion errors.
+	Rule string `json:"rule"`
+
+	// Message is a human readable description of the error, localized when messages are registered for the request's language.
+	Message string `json:"message"`
+}
+
+// ValidationErrors is returned by req.Bind and req.Body and lists every field that failed.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for idx, fieldErr := range e {
+		messages[idx] = fmt.Sprintf("%v: %v", fieldErr.Field, fieldErr.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// SendValidationErrors renders errs as a 422 Unprocessable Entity response.
+// The body has the shape {"error": "validation failed", "fields": [{"field", "source", "rule", "message"}]}
+func SendValidationErrors(res IResponse, errs ValidationErrors) IResponse {
+	return res.SetStatus(status.HTTP_422_UNPROCESSABLE_ENTITY).Send(
+		JSON{
+			"error":  "validation failed",
+			"fields": errs,
+		},
+	)
+}
+
+// AddValidator registers a custom validator usable in `valid` tags under the given name e.g `valid:"slug"`.
+// Validators are scoped to the server: structs bound by requests of other servers fail validation for that name,
+// unless those servers register a validator under it too.
+func (s *Server) AddValidator(name string, fn ValidatorFunc) {
+	s.routesMu.Lock()
+	defer s.routesMu.Unlock()
+
+	if s.validators == nil {
+		s.validators = map[string]ValidatorFunc{}
+	}
+	s.validators[name] = fn
+
+	// govalidator keeps validators for the whole process and rejects tags it doesn't know,
+	// so the name is registered there with a validator accepting everything, and checked by customValidationErrors.
+	validator.CustomTypeTagMap.Set(name, validator.CustomTypeValidator(func(any, any) bool { return true }))
+	customValidatorNames.Store(name, true)
+}
+
+// Names of the validators registered on any server, see AddValidator()
+var customValidatorNames sync.Map
+
+// customValidationErrors runs the custom validators named in the `valid` tags of the struct fields, nested structs included.
+// prefix is the name of the struct as the client sees it, fieldSources maps Go field names of bound fields to their "source:name".
+func (req *Request) customValidationErrors(structValue reflect.Value, prefix string, fieldSources map[string]string) ValidationErrors {
+	var fieldErrs ValidationErrors
+
+	for structValue.Kind() == reflect.Pointer || structValue.Kind() == reflect.Interface {
+		if structValue.IsNil() {
+			return nil
+		}
+		structValue = structValue.Elem()
+	}
+	if structValue.Kind() != reflect.Struct {
+		return nil
+	}
+
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous {
+			fieldErrs = append(fieldErrs, req.customValidationErrors(fieldValue, prefix, fieldSources)...)
+			continue
+		}
+
+		source, name := fieldSource(field)
+		if prefix != "" {
+			source, name = sourceBody, prefix+"."+name
+		} else if sourceName, exists := fieldSources[field.Name]; exists {
+			source, name, _ = strings.Cut(sourceName, ":")
+		}
+
+		for _, option := range strings.Split(field.Tag.Get("valid"), ",") {
+			validatorName, customMessage, hasCustomMessage := strings.Cut(strings.TrimSpace(option), "~")
+			if _, custom := customValidatorNames.Load(validatorName); !custom {
+				continue
+			}
+
+			if req.runValidator(validatorName, fieldValue.Interface(), structValue.Interface()) {
+				continue
+			}
+
+			message := fmt.Sprintf("%v does not validate as %v", fieldValue.Interface(), validatorName)
+			if hasCustomMessage {
+				message = validator.TruncatingErrorf(customMessage, fmt.Sprint(fieldValue.Interface()), validatorName).Error()
+			} else {
+				message = req.validationMessage(validatorName, name, message)
+			}
+
+			fieldErrs = append(fieldErrs, FieldError{Field: name, Source: source, Rule: validatorName, Message: message})
+		}
+
+		fieldErrs = append(fieldErrs, req.customValidationErrors(fieldValue, name, nil)...)
+	}
+
+	return fieldErrs
+}
+
+// runValidator runs the validator registered on the server of the request under name.
+// Values fail names only registered on other servers.
+func (req *Request) runValidator(name string, value any, parent any) bool {
+	if req.server == nil {
+		return false
+	}
+
+	req.server.routesMu.RLock()
+	fn, exists := req.server.validators[name]
+	req.server.routesMu.RUnlock()
+
+	return exists && fn(value, parent)
+}
+
+// AddValidationMessages registers messages for a language, keyed by rule name e.g {"required": "{field} est obligatoire"}.
+// The language is matched against the request's Accept-Language header, "*" registers fallback messages for all languages.
+// {field} is replaced with the name of the failing field.
+func (s *Server) AddValidationMessages(lang string, messages map[string]string) {
+	if s.validationMessages == nil {
+		s.validationMessages = map[string]map[string]string{}
+	}
+
+	lang = strings.ToLower(lang)
+	if s.validationMessages[lang] == nil {
+		s.validationMessages[lang] = map[string]string{}
+	}
+
+	for rule, message := range messages {
+		s.validationMessages[lang][rule] = message
+	}
+}
+
+// validationMessage returns the message registered for the rule in the request's preferred language.
+// fallback is returned if no message is registered.
+func (req *Request) validationMessage(rule string, field string, fallback string) string {
+	if req.server == nil || len(req.server.validationMessages) == 0 {
+		return fallback
+	}
+
+	languages := []string{}
+	if acceptLanguage, exists := req.header("Accept-Language"); exists {
+		languages = parseQualityValues(acceptLanguage)
+	}
+
+	for _, lang := range append(languages, "*") {
+		lang = strings.ToLower(lang)
+		primary, _, _ := strings.Cut(lang, "-")
+
+		for _, candidate := range []string{lang, primary} {
+			if message, exists := req.server.validationMessages[candidate][rule]; exists {
+				return strings.ReplaceAll(message, "{field}", field)
+			}
+		}
+	}
+
+	return fallback
+}
+
+// jsonFieldError converts a JSON decoding error into a FieldError pointing at the failing body field where possible.
+func (req *Request) jsonFieldError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		message := fmt.Sprintf("expected %v but got %v", typeErr.Type, typeErr.Value)
+
+		return FieldError{
+			Field:   typeErr.Field,
+			Source:  sourceBody,
+			Rule:    ruleType,
+			Message: req.validationMessage(ruleType, typeErr.Field, message),
+		}
+	}
+
+	return FieldError{Source: sourceBody, Rule: ruleJSON, Message: req.validationMessage(ruleJSON, "", err.Error())}
+}
+
+// validationFieldErrors flattens govalidator errors into FieldErrors.
+// fieldSources maps Go field names of bound fields to their "source:name", it's nil when only the body is validated.
+func (req *Request) validationFieldErrors(err error, structType reflect.Type, fieldSources map[string]string) ValidationErrors {
+	var fieldErrs ValidationErrors
+
+	var errs validator.Errors
+	var validationErr validator.Error
+
+	switch {
+	case errors.As(err, &errs):
+		for _, e := range errs {
+			fieldErrs = append(fieldErrs, req.validationFieldErrors(e, structType, fieldSources)...)
+		}
+
+	case errors.As(err, &validationErr):
+		source, name := sourceBody, validationErr.Name
+
+		if len(validationErr.Path) == 0 {
+			if sourceName, exists := fieldSources[validationErr.Name]; exists {
+				source, name, _ = strings.Cut(sourceName, ":")
+			}
+		} else {
+			name = strings.Join(append(jsonPath(structType, validationErr.Path), validationErr.Name), ".")
+		}
+
+		// Messages set in the tag e.g `valid:"required~Title is needed"` take precedence over registered ones.
+		message := validationErr.Err.Error()
+		if !validationErr.CustomErrorMessageExists {
+			message = req.validationMessage(validationErr.Validator, name, message)
+		}
+
+		fieldErrs = append(fieldErrs, FieldError{Field: name, Source: source, Rule: validationErr.Validator, Message: message})
+
+	default:
+		fieldErrs = append(fieldErrs, FieldError{Source: sourceBody, Message: err.Error()})
+	}
+
+	return fieldErrs
+}
+
+// jsonPath converts a path of Go field names into the json names the client sent.
+func jsonPath(structType reflect.Type, path []string) []string {
+	names := make([]string, len(path))
+
+	for idx, fieldName := range path {
+		for structType.Kind() == reflect.Pointer || structType.Kind() == reflect.Slice {
+			structType = structType.Elem()
+		}
+
+		names[idx] = fieldName
+		if structType.Kind() != reflect.Struct {
+			continue
+		}
+
+		if field, exists := structType.FieldByName(fieldName); exists {
+			_, names[idx] = fieldSource(field)
+			structType = field.Type
+		}
+	}
+
+	return names
+}